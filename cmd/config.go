@@ -2,12 +2,17 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/config"
 	"github.com/OlaHulleberg/codezure/internal/interactive"
 	"github.com/OlaHulleberg/codezure/internal/profiles"
 	"github.com/spf13/cobra"
+	"strconv"
 	"strings"
+	"time"
 )
 
+var configRefreshFlag bool
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Interactive configuration",
@@ -43,6 +48,55 @@ var configListCmd = &cobra.Command{
 		if cfg.Thinking != "" {
 			fmt.Printf("  thinking:     %s\n", cfg.Thinking)
 		}
+		if cfg.Hardened {
+			fmt.Printf("  hardened:     true\n")
+		}
+		if cfg.CacheTTL != "" {
+			fmt.Printf("  cache_ttl:    %s\n", cfg.CacheTTL)
+		}
+		if cfg.IsolatedAz {
+			fmt.Printf("  isolated_az:  true\n")
+		}
+		if cfg.ARMEndpoint != "" {
+			fmt.Printf("  arm_endpoint: %s\n", cfg.ARMEndpoint)
+		}
+		if cfg.DiscoveryCacheTTL != "" {
+			fmt.Printf("  discovery_cache_ttl: %s\n", cfg.DiscoveryCacheTTL)
+		}
+		if cfg.APIKeyEnv != "" {
+			fmt.Printf("  api_key_env:  %s\n", cfg.APIKeyEnv)
+		}
+		if cfg.SecretCommand != "" {
+			fmt.Printf("  secret_command: %s\n", cfg.SecretCommand)
+		}
+		if cfg.SecretCommandTimeout != "" {
+			fmt.Printf("  secret_command_timeout: %s\n", cfg.SecretCommandTimeout)
+		}
+		if cfg.KeyVaultURL != "" {
+			fmt.Printf("  key_vault_url: %s\n", cfg.KeyVaultURL)
+			fmt.Printf("  key_vault_secret: %s\n", cfg.KeyVaultSecret)
+		}
+		if cfg.KeyRotationDays != 0 {
+			fmt.Printf("  key_rotation_days: %d\n", cfg.KeyRotationDays)
+		}
+		if cfg.Tenant != "" {
+			fmt.Printf("  tenant:       %s\n", cfg.Tenant)
+		}
+		if cfg.ClientID != "" {
+			fmt.Printf("  client_id:    %s\n", cfg.ClientID)
+		}
+		if cfg.Authority != "" {
+			fmt.Printf("  authority:    %s\n", cfg.Authority)
+		}
+		if cfg.Certificate != "" {
+			fmt.Printf("  certificate:  %s\n", cfg.Certificate)
+		}
+		if cfg.IMDSEndpoint != "" {
+			fmt.Printf("  imds_endpoint: %s\n", cfg.IMDSEndpoint)
+		}
+		if cfg.FederatedTokenFile != "" {
+			fmt.Printf("  federated_token_file: %s\n", cfg.FederatedTokenFile)
+		}
 		return nil
 	},
 }
@@ -64,8 +118,8 @@ var configSetCmd = &cobra.Command{
 		}
 		switch key {
 		case "auth":
-			if val != "azure-cli" && val != "api-key" {
-				return fmt.Errorf("auth must be 'azure-cli' or 'api-key'")
+			if !config.ValidAuth(val) {
+				return fmt.Errorf("auth must be one of: %s", strings.Join(config.AuthModes, ", "))
 			}
 			cfg.Auth = val
 		case "subscription":
@@ -82,6 +136,58 @@ var configSetCmd = &cobra.Command{
 			cfg.Deployment = val
 		case "thinking":
 			cfg.Thinking = val
+		case "hardened":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return fmt.Errorf("hardened must be true or false")
+			}
+			cfg.Hardened = b
+		case "isolated_az":
+			b, err := strconv.ParseBool(val)
+			if err != nil {
+				return fmt.Errorf("isolated_az must be true or false")
+			}
+			cfg.IsolatedAz = b
+		case "arm_endpoint":
+			cfg.ARMEndpoint = val
+		case "discovery_cache_ttl":
+			if d, err := time.ParseDuration(val); err != nil || d < 0 {
+				return fmt.Errorf("discovery_cache_ttl must be a duration like 1h, or 0 to disable")
+			}
+			cfg.DiscoveryCacheTTL = val
+		case "cache_ttl":
+			if d, err := time.ParseDuration(val); err != nil || d < 0 {
+				return fmt.Errorf("cache_ttl must be a duration like 15m, or 0 to disable")
+			}
+			cfg.CacheTTL = val
+		case "api_key_env":
+			cfg.APIKeyEnv = val
+		case "secret_command":
+			cfg.SecretCommand = val
+		case "secret_command_timeout":
+			cfg.SecretCommandTimeout = val
+		case "key_vault_url":
+			cfg.KeyVaultURL = val
+		case "key_vault_secret":
+			cfg.KeyVaultSecret = val
+		case "key_rotation_days":
+			days, err := strconv.Atoi(val)
+			if err != nil || days < 0 {
+				return fmt.Errorf("key_rotation_days must be a non-negative number of days")
+			}
+			cfg.KeyRotationDays = days
+		case "tenant":
+			cfg.Tenant = val
+		case "client_id":
+			cfg.ClientID = val
+		case "authority":
+			cfg.Authority = val
+		case "certificate":
+			cfg.Certificate = val
+		case "imds_endpoint":
+			cfg.IMDSEndpoint = val
+		case "federated_token_file":
+			cfg.FederatedTokenFile = val
 		default:
 			return fmt.Errorf("unknown key: %s", key)
 		}
@@ -90,6 +196,7 @@ var configSetCmd = &cobra.Command{
 }
 
 func init() {
+	configCmd.Flags().BoolVar(&configRefreshFlag, "refresh", false, "Reload subscriptions, resources and deployments instead of using the cache")
 	configCmd.AddCommand(configListCmd)
 	configCmd.AddCommand(configSetCmd)
 }
@@ -99,5 +206,5 @@ func runInteractiveConfig() error {
 	if err != nil {
 		return err
 	}
-	return interactive.RunInteractiveConfig(Version, pm)
+	return interactive.RunInteractiveConfig(Version, pm, configRefreshFlag)
 }
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/azure"
+	"github.com/OlaHulleberg/codezure/internal/profiles"
+	"github.com/spf13/cobra"
+	"os"
+	"strings"
+)
+
+var (
+	keysRotateKeyFlag string
+	keysRotateYesFlag bool
+)
+
+var keysCmd = &cobra.Command{
+	Use:   "keys",
+	Short: "Resource access key operations (azure-cli mode)",
+}
+
+var keysRotateCmd = &cobra.Command{
+	Use:   "rotate",
+	Short: "Regenerate the inactive access key of the current resource",
+	Long: `Regenerates the access key codezure is not currently using (key2 while key1 works).
+
+For zero-downtime rotation run it twice: first to refresh key2, then with --key key1.
+Launches fall back to key2 automatically while key1 is being replaced.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm, err := profiles.NewManager()
+		if err != nil {
+			return err
+		}
+		cfg, err := pm.GetCurrentConfig(Version)
+		if err != nil {
+			return err
+		}
+		if cfg.Subscription == "" || cfg.Group == "" || cfg.Resource == "" {
+			return fmt.Errorf("subscription/group/resource must be set; run 'codezure manage config'")
+		}
+
+		target := keysRotateKeyFlag
+		if target == "" {
+			keys, err := azure.ListKeys(cfg.Subscription, cfg.Resource, cfg.Group)
+			if err != nil {
+				return err
+			}
+			endpoint, err := azure.GetEndpoint(cfg.Subscription, cfg.Resource, cfg.Group)
+			if err != nil {
+				return err
+			}
+			active, _, err := azure.SelectKey(endpoint, keys)
+			if err != nil {
+				return err
+			}
+			target = azure.OtherKey(active)
+			fmt.Printf("Active key: %s\n", active)
+		}
+		if target != "key1" && target != "key2" {
+			return fmt.Errorf("--key must be 'key1' or 'key2'")
+		}
+
+		if !keysRotateYesFlag {
+			fmt.Printf("Regenerate %s of %s (rg=%s)? Anything still using it will stop working. [y/N] ", target, cfg.Resource, cfg.Group)
+			answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+				return fmt.Errorf("rotation cancelled")
+			}
+		}
+		if _, err := azure.RegenerateKey(cfg.Subscription, cfg.Resource, cfg.Group, target); err != nil {
+			return fmt.Errorf("failed to regenerate %s: %w", target, err)
+		}
+		fmt.Printf("✓ Regenerated %s for %s\n", target, cfg.Resource)
+		if name, err := pm.GetCurrent(); err == nil {
+			_ = azure.InvalidateCachedCredential(name)
+		}
+		if target == "key2" {
+			fmt.Println("Next: once everything else has moved off key1, run 'codezure manage keys rotate --key key1'.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	keysRotateCmd.Flags().StringVar(&keysRotateKeyFlag, "key", "", "Key to regenerate (key1 or key2); defaults to the inactive one")
+	keysRotateCmd.Flags().BoolVarP(&keysRotateYesFlag, "yes", "y", false, "Skip the confirmation prompt")
+	keysCmd.AddCommand(keysRotateCmd)
+	manageCmd.AddCommand(keysCmd)
+}
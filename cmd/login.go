@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/auth"
+	"github.com/OlaHulleberg/codezure/internal/azcli"
+	"github.com/OlaHulleberg/codezure/internal/config"
+	"github.com/OlaHulleberg/codezure/internal/profiles"
+	"github.com/OlaHulleberg/codezure/internal/secrets"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var (
+	loginBrowserFlag bool
+	loginAzFlag      bool
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Sign in with Entra ID (device code or browser) without the Azure CLI",
+	Long: `Signs in for the current profile with the device code flow, or the browser with --browser.
+
+With --az, runs 'az login' in a login context of the profile's own (~/.codezure/az/<profile>)
+and switches the profile to use it, so several Azure identities can stay signed in side by side.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if loginAzFlag && loginBrowserFlag {
+			return fmt.Errorf("use either --az or --browser")
+		}
+		pm, err := profiles.NewManager()
+		if err != nil {
+			return err
+		}
+		cfg, err := pm.GetCurrentConfig(Version)
+		if err != nil {
+			return err
+		}
+		profile, e := pm.GetCurrent()
+		if e != nil || profile == "" {
+			profile = "default"
+		}
+		if loginAzFlag {
+			return azLogin(pm, profile, cfg)
+		}
+		show := func(msg string) { fmt.Printf("%s\n\n", msg) }
+		var rt string
+		if loginBrowserFlag {
+			rt, err = auth.BrowserLogin(cfg.Authority, cfg.Tenant, cfg.ClientID, show)
+		} else {
+			rt, err = auth.DeviceCodeLogin(cfg.Authority, cfg.Tenant, cfg.ClientID, show)
+		}
+		if err != nil {
+			return err
+		}
+		if err := secrets.SaveSecret(profile, secrets.RefreshToken, rt); err != nil {
+			return fmt.Errorf("failed to store refresh token in keychain: %w", err)
+		}
+		fmt.Printf("✓ Signed in; refresh token stored in OS keychain for profile '%s'\n", profile)
+		if cfg.Auth != "login" {
+			fmt.Printf("\nProfile '%s' uses auth '%s'. Run 'codezure manage config set auth login' to launch with this sign-in.\n", profile, cfg.Auth)
+		}
+		return nil
+	},
+}
+
+// azLogin runs 'az login' in the profile's isolated config dir and enables it on the profile.
+func azLogin(pm *profiles.Manager, profile string, cfg *config.Config) error {
+	if err := azcli.Require(); err != nil {
+		return err
+	}
+	dir, err := azcli.Dir(profile)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	azcli.UseConfigDir(dir)
+	args := []string{"login"}
+	if cfg.Tenant != "" {
+		args = append(args, "--tenant", cfg.Tenant)
+	}
+	c := azcli.Command(args...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Run(); err != nil {
+		return fmt.Errorf("az login failed: %w", err)
+	}
+	if !cfg.IsolatedAz {
+		cfg.IsolatedAz = true
+		if err := pm.SaveCurrentConfig(cfg); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("✓ Azure CLI signed in for profile '%s' (AZURE_CONFIG_DIR=%s)\n", profile, dir)
+	return nil
+}
+
+func init() {
+	loginCmd.Flags().BoolVar(&loginBrowserFlag, "browser", false, "Sign in through the system browser (authorization code + PKCE)")
+	loginCmd.Flags().BoolVar(&loginAzFlag, "az", false, "Run 'az login' in a login context owned by this profile")
+	manageCmd.AddCommand(loginCmd)
+}
@@ -1,12 +1,29 @@
 package cmd
 
 import (
+	"github.com/OlaHulleberg/codezure/internal/profiles"
 	"github.com/spf13/cobra"
 )
 
 var manageCmd = &cobra.Command{
 	Use:   "manage",
 	Short: "Manage configuration and resources",
+	// Run az against the current profile's login context
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		pm, err := profiles.NewManager()
+		if err != nil {
+			return err
+		}
+		profile, err := pm.GetCurrent()
+		if err != nil {
+			return nil // first run; nothing to isolate yet
+		}
+		cfg, err := pm.Load(profile)
+		if err != nil {
+			return nil
+		}
+		return useAzContext(profile, cfg)
+	},
 }
 
 func init() {
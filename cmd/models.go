@@ -5,8 +5,12 @@ import (
 	"github.com/OlaHulleberg/codezure/internal/azure"
 	"github.com/OlaHulleberg/codezure/internal/profiles"
 	"github.com/spf13/cobra"
+	"os"
+	"text/tabwriter"
 )
 
+var modelsRefreshFlag bool
+
 var modelsCmd = &cobra.Command{
 	Use:   "models",
 	Short: "Model operations",
@@ -24,19 +28,59 @@ var modelsListCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		deps, err := azure.ListDeployments(cfg.Subscription, cfg.Resource, cfg.Group)
+		deps, fetched, err := azure.NewDiscovery(cfg, modelsRefreshFlag).Deployments(cfg.Subscription, cfg.Resource, cfg.Group)
 		if err != nil {
 			return err
 		}
-		fmt.Println("Available deployments:")
+		if fetched.IsZero() {
+			fmt.Println("Available deployments:")
+		} else {
+			fmt.Printf("Available deployments (%s; --refresh to reload):\n", azure.CachedAgo(fetched))
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "  NAME\tMODEL\tVERSION\tFORMAT\tSKU\tCAPACITY\tSTATE\tRAI POLICY\tUPGRADE")
 		for _, d := range deps {
-			fmt.Printf("  %s (model=%s)\n", d.Name, d.ModelName)
+			fmt.Fprintf(w, "  %s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", d.Name, d.ModelName, d.ModelVersion, d.ModelFormat, d.SKU,
+				d.CapacityString(), d.ProvisioningState, d.RAIPolicy, d.VersionUpgradeOption)
+		}
+		return w.Flush()
+	},
+}
+
+var modelsShowCmd = &cobra.Command{
+	Use:   "show <deployment>",
+	Short: "Show details of a deployment in current resource",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm, err := profiles.NewManager()
+		if err != nil {
+			return err
+		}
+		cfg, err := pm.GetCurrentConfig(Version)
+		if err != nil {
+			return err
+		}
+		d, err := azure.GetDeployment(cfg.Subscription, cfg.Resource, cfg.Group, args[0])
+		if err != nil {
+			return fmt.Errorf("failed to get deployment '%s': %w", args[0], err)
 		}
+		fmt.Printf("Deployment:       %s\n", d.Name)
+		fmt.Printf("Resource:         %s (rg=%s)\n", cfg.Resource, cfg.Group)
+		fmt.Printf("Model:            %s\n", d.ModelName)
+		fmt.Printf("Model version:    %s\n", d.ModelVersion)
+		fmt.Printf("Model format:     %s\n", d.ModelFormat)
+		fmt.Printf("SKU:              %s\n", d.SKU)
+		fmt.Printf("Capacity:         %s\n", d.CapacityString())
+		fmt.Printf("State:            %s\n", d.ProvisioningState)
+		fmt.Printf("RAI policy:       %s\n", d.RAIPolicy)
+		fmt.Printf("Version upgrade:  %s\n", d.VersionUpgradeOption)
 		return nil
 	},
 }
 
 func init() {
 	manageCmd.AddCommand(modelsCmd)
+	modelsListCmd.Flags().BoolVar(&modelsRefreshFlag, "refresh", false, "Reload deployments instead of using the cache")
 	modelsCmd.AddCommand(modelsListCmd)
+	modelsCmd.AddCommand(modelsShowCmd)
 }
@@ -87,11 +87,7 @@ var configDeleteCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		current, _ := pm.GetCurrent()
-		if current == args[0] {
-			return fmt.Errorf("cannot delete current profile")
-		}
-		return os.Remove(filepath.Join(pmProfilesDir(pm), args[0]+".json"))
+		return pm.Delete(args[0])
 	},
 }
 
@@ -104,8 +100,7 @@ var configRenameCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		old, new := args[0], args[1]
-		return os.Rename(filepath.Join(pmProfilesDir(pm), old+".json"), filepath.Join(pmProfilesDir(pm), new+".json"))
+		return pm.Rename(args[0], args[1])
 	},
 }
 
@@ -118,12 +113,7 @@ var configCopyCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		src, dst := args[0], args[1]
-		b, err := os.ReadFile(filepath.Join(pmProfilesDir(pm), src+".json"))
-		if err != nil {
-			return err
-		}
-		return os.WriteFile(filepath.Join(pmProfilesDir(pm), dst+".json"), b, 0o644)
+		return pm.Copy(args[0], args[1])
 	},
 }
 
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/azure"
+	"github.com/OlaHulleberg/codezure/internal/profiles"
+	"github.com/spf13/cobra"
+	"os"
+	"text/tabwriter"
+)
+
+var (
+	resourcesAllSubsFlag  bool
+	resourcesParallelFlag int
+	resourcesRefreshFlag  bool
+)
+
+var resourcesCmd = &cobra.Command{
+	Use:   "resources",
+	Short: "Azure OpenAI resource operations",
+}
+
+var resourcesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List Azure OpenAI resources with their deployment counts",
+	Long: `Lists the OpenAI and AI Services resources in the current profile's subscription, or in
+every accessible subscription with --all-subscriptions, with region and deployment count.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm, err := profiles.NewManager()
+		if err != nil {
+			return err
+		}
+		cfg, err := pm.GetCurrentConfig(Version)
+		if err != nil {
+			return err
+		}
+
+		var subs []azure.Subscription
+		if resourcesAllSubsFlag {
+			var errs []error
+			subs, errs = azure.AllSubscriptions()
+			for _, e := range errs {
+				fmt.Fprintf(os.Stderr, "warning: %v\n", e)
+			}
+			if len(subs) == 0 {
+				return fmt.Errorf("no accessible subscriptions found")
+			}
+			fmt.Fprintf(os.Stderr, "Searching %d subscriptions...\n", len(subs))
+		} else {
+			if cfg.Subscription == "" {
+				return fmt.Errorf("no subscription in the current profile; use --all-subscriptions")
+			}
+			subs = []azure.Subscription{{ID: cfg.Subscription, Name: cfg.Subscription, TenantID: cfg.Tenant}}
+		}
+
+		results, errs := azure.NewDiscovery(cfg, resourcesRefreshFlag).SearchResources(subs, resourcesParallelFlag)
+		for _, e := range errs {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", e)
+		}
+		if len(results) == 0 {
+			fmt.Println("No Azure OpenAI resources found.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "SUBSCRIPTION\tGROUP\tRESOURCE\tREGION\tDEPLOYMENTS")
+		for _, r := range results {
+			count := fmt.Sprint(len(r.Deployments))
+			if r.DeploymentsErr != nil {
+				count = "?"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Subscription.Name, r.Resource.ResourceGroup, r.Resource.Name, r.Resource.Location, count)
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	resourcesListCmd.Flags().BoolVar(&resourcesAllSubsFlag, "all-subscriptions", false, "Search every accessible subscription")
+	resourcesListCmd.Flags().IntVar(&resourcesParallelFlag, "parallel", azure.DefaultParallelism, "Maximum subscriptions queried at once")
+	resourcesListCmd.Flags().BoolVar(&resourcesRefreshFlag, "refresh", false, "Reload instead of using the discovery cache")
+	resourcesCmd.AddCommand(resourcesListCmd)
+	manageCmd.AddCommand(resourcesCmd)
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/azcli"
+	"github.com/OlaHulleberg/codezure/internal/azure"
 	"github.com/OlaHulleberg/codezure/internal/config"
 	"github.com/OlaHulleberg/codezure/internal/interactive"
 	"github.com/OlaHulleberg/codezure/internal/launcher"
@@ -14,6 +16,7 @@ import (
 
 var (
 	codezureProfileFlag string
+	codezureRefreshFlag bool
 	Version             = "dev"
 )
 
@@ -33,6 +36,7 @@ func Execute() {
 
 func init() {
 	rootCmd.Flags().StringVar(&codezureProfileFlag, "codezure-profile", "", "Use a specific codezure profile for this run")
+	rootCmd.Flags().BoolVar(&codezureRefreshFlag, "codezure-refresh", false, "Fetch fresh credentials instead of using the cache")
 
 	// Allow unknown flags to pass through to Codex CLI
 	rootCmd.FParseErrWhitelist.UnknownFlags = true
@@ -66,7 +70,7 @@ func runRoot(cmd *cobra.Command, args []string) error {
 		// First-run: if no current profile, trigger interactive GUI
 		if _, e := pm.GetCurrent(); e != nil {
 			// Launch interactive config to save current profile
-			if err := interactive.RunInteractiveConfig(Version, pm); err != nil {
+			if err := interactive.RunInteractiveConfig(Version, pm, false); err != nil {
 				return err
 			}
 		}
@@ -81,8 +85,14 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	if err := pm.Validate(cfg); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
+	if profile == "" {
+		profile, _ = pm.GetCurrent()
+	}
+	if err := useAzContext(profile, cfg); err != nil {
+		return err
+	}
 
-	return launcher.Launch(passthroughArgs)
+	return launcher.Launch(profile, cfg, passthroughArgs, codezureRefreshFlag)
 }
 
 // collectPassthroughArgs separates codezure flags from Codex CLI args
@@ -95,6 +105,7 @@ func collectPassthroughArgs() []string {
 	// codezure flags and whether they require a value as the next arg
 	codezureFlags := map[string]bool{
 		"--codezure-profile": true,
+		"--codezure-refresh": false,
 	}
 
 	skip := false
@@ -128,3 +139,19 @@ func collectPassthroughArgs() []string {
 
 	return passthroughArgs
 }
+
+// useAzContext points Azure calls at the profile's ARM endpoint and credential, and az at
+// the profile's isolated login context when it has one.
+func useAzContext(profile string, cfg *config.Config) error {
+	azure.Configure(cfg, profile)
+	if !cfg.IsolatedAz || profile == "" {
+		azcli.UseConfigDir("")
+		return nil
+	}
+	dir, err := azcli.Dir(profile)
+	if err != nil {
+		return err
+	}
+	azcli.UseConfigDir(dir)
+	return nil
+}
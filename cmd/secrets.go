@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/apikey"
+	"github.com/OlaHulleberg/codezure/internal/azure"
+	"github.com/OlaHulleberg/codezure/internal/config"
+	"github.com/OlaHulleberg/codezure/internal/interactive"
+	"github.com/OlaHulleberg/codezure/internal/profiles"
+	"github.com/OlaHulleberg/codezure/internal/secrets"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var secretsSetStdinFlag bool
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage stored API keys",
+}
+
+var secretsSetCmd = &cobra.Command{
+	Use:   "set [profile]",
+	Short: "Store or replace a profile's API key",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm, err := profiles.NewManager()
+		if err != nil {
+			return err
+		}
+		profile, err := profileArg(pm, args)
+		if err != nil {
+			return err
+		}
+		var key string
+		if secretsSetStdinFlag {
+			b, err := io.ReadAll(os.Stdin)
+			if err != nil {
+				return err
+			}
+			key = strings.TrimSpace(string(b))
+		} else {
+			key, err = interactive.InteractivePassword(fmt.Sprintf("Enter API Key for profile '%s'", profile), "paste API key...")
+			if err != nil {
+				return fmt.Errorf("API key input failed: %w", err)
+			}
+		}
+		if err := secrets.SaveKey(profile, key); err != nil {
+			return fmt.Errorf("failed to store API key: %w", err)
+		}
+		fmt.Printf("✓ API key stored in %s store for profile '%s'\n", secrets.BackendName(), profile)
+		return nil
+	},
+}
+
+var secretsVerifyCmd = &cobra.Command{
+	Use:   "verify [profile]",
+	Short: "Test a profile's API key against its endpoint",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm, err := profiles.NewManager()
+		if err != nil {
+			return err
+		}
+		profile, err := profileArg(pm, args)
+		if err != nil {
+			return err
+		}
+		cfg, err := pm.Load(profile)
+		if err != nil {
+			return err
+		}
+		if cfg.Auth != "api-key" {
+			return fmt.Errorf("profile '%s' uses auth '%s'; verify applies to api-key profiles", profile, authOrDefault(cfg))
+		}
+		key, source, err := apikey.Resolve(cfg, profile)
+		if err != nil {
+			return err
+		}
+		if err := azure.Preflight(cfg.Endpoint, key); err != nil {
+			return fmt.Errorf("API key from %s failed verification: %w", source, err)
+		}
+		fmt.Printf("✓ API key from %s accepted by %s\n", source, cfg.Endpoint)
+		return nil
+	},
+}
+
+var secretsRemoveCmd = &cobra.Command{
+	Use:   "remove [profile]",
+	Short: "Remove a profile's stored API key",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm, err := profiles.NewManager()
+		if err != nil {
+			return err
+		}
+		profile, err := profileArg(pm, args)
+		if err != nil {
+			return err
+		}
+		if err := secrets.DeleteKey(profile); err != nil {
+			if errors.Is(err, secrets.ErrNotFound) {
+				return fmt.Errorf("no API key stored for profile '%s'", profile)
+			}
+			return err
+		}
+		fmt.Printf("✓ API key removed for profile '%s'\n", profile)
+		return nil
+	},
+}
+
+var secretsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show which profiles have API keys and how old they are",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pm, err := profiles.NewManager()
+		if err != nil {
+			return err
+		}
+		names, err := pm.List()
+		if err != nil {
+			return err
+		}
+		current, _ := pm.GetCurrent()
+		var warnings []string
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PROFILE\tAUTH\tKEY SOURCE\tAGE")
+		for _, name := range names {
+			cfg, err := pm.Load(name)
+			if err != nil {
+				continue
+			}
+			label := name
+			if name == current {
+				label += " *"
+			}
+			if cfg.Auth != "api-key" {
+				fmt.Fprintf(w, "%s\t%s\t-\t-\n", label, authOrDefault(cfg))
+				continue
+			}
+			if src := apikey.ExternalSource(cfg, name); src != "" {
+				fmt.Fprintf(w, "%s\t%s\t%s\t-\n", label, cfg.Auth, src)
+				continue
+			}
+			if _, err := secrets.GetKey(name); err != nil {
+				status := "missing"
+				if !errors.Is(err, secrets.ErrNotFound) {
+					status = "error: " + err.Error()
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t-\n", label, cfg.Auth, status)
+				continue
+			}
+			age := "unknown"
+			if saved, err := secrets.KeySavedAt(name); err == nil {
+				days := int(time.Since(saved).Hours() / 24)
+				age = fmt.Sprintf("%dd", days)
+				rotation := cfg.KeyRotationDays
+				if rotation == 0 {
+					rotation = config.DefaultKeyRotationDays
+				}
+				if days > rotation {
+					warnings = append(warnings, fmt.Sprintf("⚠️  Key for profile '%s' is %d days old (rotation period %d days); run 'codezure manage secrets set %s'", name, days, rotation, name))
+				}
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", label, cfg.Auth, secrets.BackendName(), age)
+		}
+		w.Flush()
+		if len(warnings) > 0 {
+			fmt.Println()
+			for _, warn := range warnings {
+				fmt.Println(warn)
+			}
+		}
+		return nil
+	},
+}
+
+// profileArg returns the profile named in args, or the current profile.
+func profileArg(pm *profiles.Manager, args []string) (string, error) {
+	if len(args) == 1 {
+		if !pm.Exists(args[0]) {
+			return "", fmt.Errorf("profile '%s' not found", args[0])
+		}
+		return args[0], nil
+	}
+	name, err := pm.GetCurrent()
+	if err != nil || name == "" {
+		return "", fmt.Errorf("no current profile configured; run 'codezure manage config'")
+	}
+	return name, nil
+}
+
+func authOrDefault(cfg *config.Config) string {
+	if cfg.Auth == "" {
+		return "azure-cli"
+	}
+	return cfg.Auth
+}
+
+func init() {
+	secretsSetCmd.Flags().BoolVar(&secretsSetStdinFlag, "stdin", false, "Read the API key from stdin")
+	secretsCmd.AddCommand(secretsSetCmd)
+	secretsCmd.AddCommand(secretsVerifyCmd)
+	secretsCmd.AddCommand(secretsRemoveCmd)
+	secretsCmd.AddCommand(secretsStatusCmd)
+	manageCmd.AddCommand(secretsCmd)
+}
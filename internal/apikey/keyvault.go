@@ -0,0 +1,69 @@
+package apikey
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/OlaHulleberg/codezure/internal/auth"
+)
+
+// KeyVaultScope is the token scope for the Key Vault data plane.
+const KeyVaultScope = "https://vault.azure.net/.default"
+
+const keyVaultAPIVersion = "7.4"
+
+type keyVaultSecret struct {
+	Value string `json:"value"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// GetKeyVaultSecret reads the current version of a secret from a vault such as
+// https://myvault.vault.azure.net. name may be "name/version" to pin a version.
+func GetKeyVaultSecret(vaultURL, name string, cred auth.Credential) (string, error) {
+	vaultURL = strings.TrimRight(strings.TrimSpace(vaultURL), "/")
+	name = strings.Trim(strings.TrimSpace(name), "/")
+	if name == "" {
+		return "", fmt.Errorf("key_vault_secret not set in profile")
+	}
+	tok, err := cred.GetToken(KeyVaultScope)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.SplitN(name, "/", 2)
+	path := "/secrets/" + url.PathEscape(parts[0])
+	if len(parts) == 2 {
+		path += "/" + url.PathEscape(parts[1])
+	}
+	req, err := http.NewRequest(http.MethodGet, vaultURL+path+"?api-version="+keyVaultAPIVersion, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var s keyVaultSecret
+	if err := json.NewDecoder(resp.Body).Decode(&s); err != nil {
+		return "", fmt.Errorf("Key Vault returned status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if s.Error != nil {
+			return "", fmt.Errorf("Key Vault returned status %d: %s: %s", resp.StatusCode, s.Error.Code, s.Error.Message)
+		}
+		return "", fmt.Errorf("Key Vault returned status %d", resp.StatusCode)
+	}
+	if s.Value == "" {
+		return "", fmt.Errorf("Key Vault secret %s is empty", name)
+	}
+	return s.Value, nil
+}
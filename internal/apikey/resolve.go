@@ -0,0 +1,81 @@
+package apikey
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/OlaHulleberg/codezure/internal/auth"
+	"github.com/OlaHulleberg/codezure/internal/config"
+	"github.com/OlaHulleberg/codezure/internal/secrets"
+)
+
+// ProfileEnv returns the per-profile API key variable, e.g. CODEZURE_KEY_WORK_DEV for "work-dev".
+func ProfileEnv(profile string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(profile) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return "CODEZURE_KEY_" + b.String()
+}
+
+// Resolve finds the API key for an "api-key" profile and describes where it came from.
+// Precedence: the profile's api_key_env variable, CODEZURE_KEY_<PROFILE>, the profile's
+// secret_command, its Key Vault secret, then the secret store.
+func Resolve(cfg *config.Config, profile string) (key string, source string, err error) {
+	if name := strings.TrimSpace(cfg.APIKeyEnv); name != "" {
+		if v := strings.TrimSpace(os.Getenv(name)); v != "" {
+			return v, "environment variable " + name, nil
+		}
+	}
+	if name := ProfileEnv(profile); strings.TrimSpace(os.Getenv(name)) != "" {
+		return strings.TrimSpace(os.Getenv(name)), "environment variable " + name, nil
+	}
+	if strings.TrimSpace(cfg.SecretCommand) != "" {
+		timeout, _ := time.ParseDuration(cfg.SecretCommandTimeout)
+		key, err := secrets.RunCommand(cfg.SecretCommand, timeout)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to retrieve API key for profile '%s': %w", profile, err)
+		}
+		return key, "secret command", nil
+	}
+	if strings.TrimSpace(cfg.KeyVaultURL) != "" {
+		key, err := GetKeyVaultSecret(cfg.KeyVaultURL, cfg.KeyVaultSecret, &auth.AzureCLICredential{TenantID: cfg.Tenant})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to retrieve API key from Key Vault for profile '%s': %w", profile, err)
+		}
+		return key, "Key Vault secret " + cfg.KeyVaultSecret, nil
+	}
+	key, err = secrets.GetKey(profile)
+	if err != nil {
+		hint := ProfileEnv(profile)
+		if cfg.APIKeyEnv != "" {
+			hint = cfg.APIKeyEnv + " or " + hint
+		}
+		return "", "", fmt.Errorf("failed to retrieve API key from %s store for profile '%s' (and %s is not set): %w", secrets.BackendName(), profile, hint, err)
+	}
+	return key, secrets.BackendName() + " secret store", nil
+}
+
+// ExternalSource names the non-store source Resolve would use for a profile, without running
+// commands or fetching remote secrets. It returns "" when the key comes from the secret store.
+func ExternalSource(cfg *config.Config, profile string) string {
+	if name := strings.TrimSpace(cfg.APIKeyEnv); name != "" && strings.TrimSpace(os.Getenv(name)) != "" {
+		return "env " + name
+	}
+	if name := ProfileEnv(profile); strings.TrimSpace(os.Getenv(name)) != "" {
+		return "env " + name
+	}
+	if strings.TrimSpace(cfg.SecretCommand) != "" {
+		return "secret command"
+	}
+	if strings.TrimSpace(cfg.KeyVaultURL) != "" {
+		return "key vault"
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+// Package arm is a small typed client for the Azure Resource Manager REST API, covering what
+// codezure needs: tenants, subscriptions, Cognitive Services accounts, keys and deployments.
+package arm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/auth"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the public-cloud ARM base URL.
+const DefaultEndpoint = "https://management.azure.com"
+
+const (
+	subscriptionsAPIVersion     = "2022-12-01"
+	cognitiveServicesAPIVersion = "2023-05-01"
+)
+
+// Client calls ARM with bearer tokens from a credential.
+type Client struct {
+	Endpoint string // ARM base URL, e.g. DefaultEndpoint or an httptest server
+	HTTP     *http.Client
+
+	tokens *auth.TokenSource
+}
+
+// NewClient returns a client for endpoint (DefaultEndpoint when empty). Tokens are requested
+// for the endpoint's own scope, so sovereign clouds and local fakes work unchanged.
+func NewClient(endpoint string, cred auth.Credential) *Client {
+	endpoint = strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+	return &Client{
+		Endpoint: endpoint,
+		HTTP:     &http.Client{Timeout: 60 * time.Second},
+		tokens:   auth.NewTokenSource(cred, Scope(endpoint)),
+	}
+}
+
+// Scope returns the token scope for an ARM endpoint.
+func Scope(endpoint string) string {
+	return strings.TrimRight(endpoint, "/") + "/.default"
+}
+
+// Error is an ARM error response.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("ARM request failed with status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("ARM request failed with status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+type errorResponse struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// do sends a request to path (relative to the endpoint, or an absolute nextLink) and decodes
+// the JSON response into out.
+func (c *Client) do(method, path, apiVersion string, body, out any) error {
+	u := path
+	if !strings.HasPrefix(path, "http://") && !strings.HasPrefix(path, "https://") {
+		u = c.Endpoint + path + "?api-version=" + url.QueryEscape(apiVersion)
+	} else if !c.sameHost(path) {
+		// Never send the bearer token to a host a response pointed us at
+		return fmt.Errorf("ARM returned a link to another host: %s", path)
+	}
+	var rd io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, u, rd)
+	if err != nil {
+		return err
+	}
+	tok, err := c.tokens.Token()
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+tok)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		apiErr := &Error{StatusCode: resp.StatusCode}
+		var er errorResponse
+		if json.Unmarshal(data, &er) == nil {
+			apiErr.Code = er.Error.Code
+			apiErr.Message = er.Error.Message
+		}
+		return apiErr
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// sameHost reports whether link has the endpoint's scheme and host.
+func (c *Client) sameHost(link string) bool {
+	l, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	e, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(l.Scheme, e.Scheme) && strings.EqualFold(l.Host, e.Host)
+}
+
+// page is one page of an ARM list response.
+type page[T any] struct {
+	Value    []T    `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+// list follows nextLink until every page of a list operation has been read.
+func list[T any](c *Client, path, apiVersion string) ([]T, error) {
+	var all []T
+	for path != "" {
+		var p page[T]
+		if err := c.do(http.MethodGet, path, apiVersion, nil, &p); err != nil {
+			return nil, err
+		}
+		all = append(all, p.Value...)
+		path = p.NextLink
+	}
+	return all, nil
+}
@@ -0,0 +1,120 @@
+package arm
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/OlaHulleberg/codezure/internal/auth"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type staticCredential struct{}
+
+func (staticCredential) GetToken(scope string) (*auth.Token, error) {
+	return &auth.Token{AccessToken: "test-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewClient(srv.URL, staticCredential{}), srv
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func TestListFollowsNextLink(t *testing.T) {
+	var srvURL string
+	c, srv := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			t.Errorf("missing bearer token on %s", r.URL)
+		}
+		switch r.URL.Query().Get("page") {
+		case "":
+			if r.URL.Query().Get("api-version") != subscriptionsAPIVersion {
+				t.Errorf("api-version = %q", r.URL.Query().Get("api-version"))
+			}
+			writeJSON(w, 200, map[string]any{
+				"value":    []Subscription{{SubscriptionID: "s1"}},
+				"nextLink": srvURL + "/subscriptions?api-version=" + subscriptionsAPIVersion + "&page=2",
+			})
+		case "2":
+			writeJSON(w, 200, map[string]any{"value": []Subscription{{SubscriptionID: "s2"}}})
+		}
+	})
+	srvURL = srv.URL
+	subs, err := c.ListSubscriptions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(subs) != 2 || subs[0].SubscriptionID != "s1" || subs[1].SubscriptionID != "s2" {
+		t.Fatalf("subscriptions = %+v", subs)
+	}
+}
+
+func TestListRejectsNextLinkToOtherHost(t *testing.T) {
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("request sent to other host with Authorization %q", r.Header.Get("Authorization"))
+	}))
+	defer other.Close()
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, 200, map[string]any{
+			"value":    []Subscription{{SubscriptionID: "s1"}},
+			"nextLink": other.URL + "/subscriptions?page=2",
+		})
+	})
+	if _, err := c.ListSubscriptions(); err == nil {
+		t.Fatal("ListSubscriptions followed a nextLink to another host")
+	}
+}
+
+func TestErrorDecoding(t *testing.T) {
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusForbidden, map[string]any{
+			"error": map[string]string{"code": "AuthorizationFailed", "message": "no access"},
+		})
+	})
+	_, err := c.GetAccount("s1", "g1", "r1")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error %v is not an *Error", err)
+	}
+	if apiErr.StatusCode != http.StatusForbidden || apiErr.Code != "AuthorizationFailed" || apiErr.Message != "no access" {
+		t.Fatalf("error = %+v", apiErr)
+	}
+}
+
+func TestResourceGraphSkipToken(t *testing.T) {
+	var calls int
+	c, _ := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/providers/Microsoft.ResourceGraph/resources" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+		var req graphRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		calls++
+		switch req.Options.SkipToken {
+		case "":
+			writeJSON(w, 200, map[string]any{"data": []GraphAccount{{Name: "r1"}}, "$skipToken": "next"})
+		case "next":
+			writeJSON(w, 200, map[string]any{"data": []GraphAccount{{Name: "r2"}}})
+		default:
+			t.Errorf("unexpected skip token %q", req.Options.SkipToken)
+		}
+	})
+	accounts, err := c.ListOpenAIAccounts([]string{"s1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || len(accounts) != 2 || accounts[1].Name != "r2" {
+		t.Fatalf("calls = %d, accounts = %+v", calls, accounts)
+	}
+}
@@ -0,0 +1,76 @@
+package arm
+
+import (
+	"net/http"
+)
+
+const resourceGraphAPIVersion = "2022-10-01"
+
+// maxGraphSubscriptions is the most subscriptions one Resource Graph request may scope.
+const maxGraphSubscriptions = 1000
+
+// OpenAIAccountsQuery finds every OpenAI and AI Services account in the queried subscriptions.
+const OpenAIAccountsQuery = `Resources
+| where type =~ 'microsoft.cognitiveservices/accounts' and kind in~ ('OpenAI','AIServices')
+| project id, name, kind, location, resourceGroup, subscriptionId, tenantId, endpoint = tostring(properties.endpoint)
+| order by id asc`
+
+// GraphAccount is one row of OpenAIAccountsQuery.
+type GraphAccount struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Kind           string `json:"kind"`
+	Location       string `json:"location"`
+	ResourceGroup  string `json:"resourceGroup"`
+	SubscriptionID string `json:"subscriptionId"`
+	TenantID       string `json:"tenantId"`
+	Endpoint       string `json:"endpoint"`
+}
+
+// ListOpenAIAccounts runs OpenAIAccountsQuery against Resource Graph across subscriptions.
+func (c *Client) ListOpenAIAccounts(subscriptions []string) ([]GraphAccount, error) {
+	return query[GraphAccount](c, OpenAIAccountsQuery, subscriptions)
+}
+
+type graphOptions struct {
+	Top          int    `json:"$top"`
+	SkipToken    string `json:"$skipToken,omitempty"`
+	ResultFormat string `json:"resultFormat"`
+}
+
+type graphRequest struct {
+	Subscriptions []string     `json:"subscriptions"`
+	Query         string       `json:"query"`
+	Options       graphOptions `json:"options"`
+}
+
+type graphResponse[T any] struct {
+	Data      []T    `json:"data"`
+	SkipToken string `json:"$skipToken"`
+}
+
+// query runs a Resource Graph query, splitting subscriptions into batches the API accepts
+// and following $skipToken until every page has been read.
+func query[T any](c *Client, q string, subscriptions []string) ([]T, error) {
+	var all []T
+	for start := 0; start < len(subscriptions); start += maxGraphSubscriptions {
+		end := min(start+maxGraphSubscriptions, len(subscriptions))
+		req := graphRequest{
+			Subscriptions: subscriptions[start:end],
+			Query:         q,
+			Options:       graphOptions{Top: 1000, ResultFormat: "objectArray"},
+		}
+		for {
+			var resp graphResponse[T]
+			if err := c.do(http.MethodPost, "/providers/Microsoft.ResourceGraph/resources", resourceGraphAPIVersion, req, &resp); err != nil {
+				return nil, err
+			}
+			all = append(all, resp.Data...)
+			if resp.SkipToken == "" {
+				break
+			}
+			req.Options.SkipToken = resp.SkipToken
+		}
+	}
+	return all, nil
+}
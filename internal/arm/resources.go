@@ -0,0 +1,151 @@
+package arm
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Tenant is a directory the caller can access.
+type Tenant struct {
+	TenantID      string `json:"tenantId"`
+	DisplayName   string `json:"displayName"`
+	DefaultDomain string `json:"defaultDomain"`
+}
+
+// Subscription is an Azure subscription.
+type Subscription struct {
+	SubscriptionID string `json:"subscriptionId"`
+	DisplayName    string `json:"displayName"`
+	TenantID       string `json:"tenantId"`
+	State          string `json:"state"`
+}
+
+// Account is a Cognitive Services account (an Azure OpenAI or AI Services resource).
+type Account struct {
+	ID         string            `json:"id"`
+	Name       string            `json:"name"`
+	Kind       string            `json:"kind"`
+	Location   string            `json:"location"`
+	Properties AccountProperties `json:"properties"`
+}
+
+type AccountProperties struct {
+	Endpoint string `json:"endpoint"`
+}
+
+// ResourceGroup returns the resource group from the account's resource ID.
+func (a *Account) ResourceGroup() string {
+	parts := strings.Split(a.ID, "/")
+	for i := 0; i+1 < len(parts); i++ {
+		if strings.EqualFold(parts[i], "resourceGroups") {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// AccountKeys holds both access keys of an account.
+type AccountKeys struct {
+	Key1 string `json:"key1"`
+	Key2 string `json:"key2"`
+}
+
+// Deployment is a model deployment on an account.
+type Deployment struct {
+	Name       string               `json:"name"`
+	SKU        DeploymentSKU        `json:"sku"`
+	Properties DeploymentProperties `json:"properties"`
+}
+
+type DeploymentSKU struct {
+	Name     string `json:"name"`
+	Capacity int    `json:"capacity"`
+}
+
+type DeploymentProperties struct {
+	Model                DeploymentModel `json:"model"`
+	ProvisioningState    string          `json:"provisioningState"`
+	RAIPolicyName        string          `json:"raiPolicyName"`
+	VersionUpgradeOption string          `json:"versionUpgradeOption"`
+}
+
+type DeploymentModel struct {
+	Format  string `json:"format"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// ListTenants returns the tenants the credential's account can access.
+func (c *Client) ListTenants() ([]Tenant, error) {
+	return list[Tenant](c, "/tenants", subscriptionsAPIVersion)
+}
+
+// ListSubscriptions returns the subscriptions visible to the credential's tenant.
+func (c *Client) ListSubscriptions() ([]Subscription, error) {
+	return list[Subscription](c, "/subscriptions", subscriptionsAPIVersion)
+}
+
+// ListAccounts returns every Cognitive Services account in a subscription.
+func (c *Client) ListAccounts(subscription string) ([]Account, error) {
+	path := fmt.Sprintf("/subscriptions/%s/providers/Microsoft.CognitiveServices/accounts", url.PathEscape(subscription))
+	return list[Account](c, path, cognitiveServicesAPIVersion)
+}
+
+// GetAccount returns one Cognitive Services account.
+func (c *Client) GetAccount(subscription, group, name string) (*Account, error) {
+	var a Account
+	if err := c.do(http.MethodGet, accountPath(subscription, group, name), cognitiveServicesAPIVersion, nil, &a); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListKeys returns both access keys of an account.
+func (c *Client) ListKeys(subscription, group, name string) (*AccountKeys, error) {
+	var k AccountKeys
+	if err := c.do(http.MethodPost, accountPath(subscription, group, name)+"/listKeys", cognitiveServicesAPIVersion, nil, &k); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// RegenerateKey regenerates "key1" or "key2" and returns the new key pair.
+func (c *Client) RegenerateKey(subscription, group, name, keyName string) (*AccountKeys, error) {
+	var armName string
+	switch keyName {
+	case "key1":
+		armName = "Key1"
+	case "key2":
+		armName = "Key2"
+	default:
+		return nil, fmt.Errorf("key name must be 'key1' or 'key2'")
+	}
+	var k AccountKeys
+	body := map[string]string{"keyName": armName}
+	if err := c.do(http.MethodPost, accountPath(subscription, group, name)+"/regenerateKey", cognitiveServicesAPIVersion, body, &k); err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// ListDeployments returns the model deployments of an account.
+func (c *Client) ListDeployments(subscription, group, name string) ([]Deployment, error) {
+	return list[Deployment](c, accountPath(subscription, group, name)+"/deployments", cognitiveServicesAPIVersion)
+}
+
+// GetDeployment returns one model deployment of an account.
+func (c *Client) GetDeployment(subscription, group, name, deployment string) (*Deployment, error) {
+	var d Deployment
+	path := accountPath(subscription, group, name) + "/deployments/" + url.PathEscape(deployment)
+	if err := c.do(http.MethodGet, path, cognitiveServicesAPIVersion, nil, &d); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func accountPath(subscription, group, name string) string {
+	return fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.CognitiveServices/accounts/%s",
+		url.PathEscape(subscription), url.PathEscape(group), url.PathEscape(name))
+}
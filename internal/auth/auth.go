@@ -0,0 +1,17 @@
+package auth
+
+import "time"
+
+// CognitiveServicesScope is the token scope accepted by Azure OpenAI / AI Services endpoints.
+const CognitiveServicesScope = "https://cognitiveservices.azure.com/.default"
+
+// Token is a bearer access token and its expiry.
+type Token struct {
+	AccessToken string
+	ExpiresOn   time.Time
+}
+
+// Credential acquires Entra ID access tokens for a scope.
+type Credential interface {
+	GetToken(scope string) (*Token, error)
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/azcli"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// AzureCLICredential gets tokens from the signed-in Azure CLI account. TenantID requests
+// the token for that tenant instead of the tenant az is currently pointed at; Subscription
+// requests it for the subscription's tenant.
+type AzureCLICredential struct {
+	TenantID     string
+	Subscription string
+}
+
+type azToken struct {
+	AccessToken string `json:"accessToken"`
+	ExpiresOn   string `json:"expiresOn"`
+	ExpiresOnTS int64  `json:"expires_on"`
+}
+
+func (c *AzureCLICredential) GetToken(scope string) (*Token, error) {
+	if err := azcli.Require(); err != nil {
+		return nil, err
+	}
+	args := []string{"account", "get-access-token", "--scope", scope, "-o", "json"}
+	if c.TenantID != "" {
+		args = append(args, "--tenant", c.TenantID)
+	} else if c.Subscription != "" {
+		args = append(args, "--subscription", c.Subscription)
+	}
+	out, err := azcli.Output(args...)
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return nil, fmt.Errorf("az account get-access-token failed: %s", strings.TrimSpace(string(ee.Stderr)))
+		}
+		return nil, err
+	}
+	var t azToken
+	if err := json.Unmarshal(out, &t); err != nil {
+		return nil, err
+	}
+	if t.AccessToken == "" {
+		return nil, fmt.Errorf("az returned an empty access token")
+	}
+	tok := &Token{AccessToken: t.AccessToken}
+	if t.ExpiresOnTS > 0 {
+		tok.ExpiresOn = time.Unix(t.ExpiresOnTS, 0)
+	} else if exp, err := time.ParseInLocation("2006-01-02 15:04:05.999999", t.ExpiresOn, time.Local); err == nil {
+		// Older az versions only report expiresOn in local time
+		tok.ExpiresOn = exp
+	}
+	return tok, nil
+}
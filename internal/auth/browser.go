@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// browserLoginTimeout bounds how long the loopback listener waits for the redirect.
+const browserLoginTimeout = 5 * time.Minute
+
+// BrowserLogin runs the authorization code flow with PKCE, receiving the redirect on a
+// loopback listener. show is called with the authorize URL in case the browser cannot be
+// opened; the returned refresh token should be cached for later launches.
+func BrowserLogin(authority, tenant, clientID string, show func(message string)) (string, error) {
+	tenant, clientID = loginDefaults(tenant, clientID)
+	verifier, err := randomURLString(32)
+	if err != nil {
+		return "", err
+	}
+	state, err := randomURLString(16)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", fmt.Errorf("failed to start loopback listener: %w", err)
+	}
+	defer ln.Close()
+	redirectURI := fmt.Sprintf("http://localhost:%d", ln.Addr().(*net.TCPAddr).Port)
+
+	type result struct {
+		code string
+		err  error
+	}
+	done := make(chan result, 1)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			// Ignore stray requests (favicon, scanners) rather than failing the sign-in
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		}
+		res := result{code: q.Get("code")}
+		if e := q.Get("error"); e != "" {
+			fmt.Fprint(w, "Sign-in failed. You can close this window.")
+			res = result{err: fmt.Errorf("%s: %s", e, firstLine(q.Get("error_description")))}
+		} else {
+			fmt.Fprint(w, "Sign-in complete. You can close this window and return to codezure.")
+		}
+		// Keep the first result; a refresh or repeated redirect must not block the handler
+		select {
+		case done <- res:
+		default:
+		}
+	})}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	authURL := authorityURL(authority, tenant) + "/oauth2/v2.0/authorize?" + url.Values{
+		"client_id":             {clientID},
+		"response_type":         {"code"},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {loginScope},
+		"state":                 {state},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+		"prompt":                {"select_account"},
+	}.Encode()
+	if err := openBrowser(authURL); err != nil {
+		show(fmt.Sprintf("Open this URL in your browser to sign in:\n%s", authURL))
+	} else {
+		show("Opened your browser to sign in. Waiting for the redirect...")
+	}
+
+	var res result
+	select {
+	case res = <-done:
+	case <-time.After(browserLoginTimeout):
+		return "", fmt.Errorf("timed out waiting for browser sign-in")
+	}
+	if res.err != nil {
+		return "", fmt.Errorf("browser sign-in failed: %w", res.err)
+	}
+	tr, err := requestToken(tokenEndpoint(authority, tenant), url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {clientID},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+		"scope":         {loginScope},
+	})
+	if err != nil {
+		return "", fmt.Errorf("authorization code exchange failed: %w", err)
+	}
+	if tr.RefreshToken == "" {
+		return "", fmt.Errorf("sign-in succeeded but no refresh token was issued")
+	}
+	return tr.RefreshToken, nil
+}
+
+func randomURLString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func openBrowser(u string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", u).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", u).Start()
+	default:
+		return exec.Command("xdg-open", u).Start()
+	}
+}
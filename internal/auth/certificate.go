@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// CertificateCredential authenticates a service principal with a signed JWT client assertion.
+type CertificateCredential struct {
+	Authority string
+	TenantID  string
+	ClientID  string
+	Cert      *x509.Certificate
+	Key       *rsa.PrivateKey
+}
+
+func (c *CertificateCredential) GetToken(scope string) (*Token, error) {
+	if err := CheckCertificate(c.Cert); err != nil {
+		return nil, err
+	}
+	endpoint := tokenEndpoint(c.Authority, c.TenantID)
+	assertion, err := c.clientAssertion(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {c.ClientID},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {assertion},
+		"scope":                 {scope},
+	}
+	tr, err := requestToken(endpoint, form)
+	if err != nil {
+		return nil, fmt.Errorf("certificate token request failed: %w", err)
+	}
+	return tr.token(), nil
+}
+
+// clientAssertion builds an RS256 JWT identifying the certificate by its SHA-1 thumbprint (x5t).
+func (c *CertificateCredential) clientAssertion(audience string) (string, error) {
+	thumb := sha1.Sum(c.Cert.Raw)
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	header := map[string]any{
+		"alg": "RS256",
+		"typ": "JWT",
+		"x5t": base64.RawURLEncoding.EncodeToString(thumb[:]),
+	}
+	claims := map[string]any{
+		"aud": audience,
+		"iss": c.ClientID,
+		"sub": c.ClientID,
+		"jti": hex.EncodeToString(jti),
+		"iat": now.Unix(),
+		"nbf": now.Unix(),
+		"exp": now.Add(10 * time.Minute).Unix(),
+	}
+	h, err := json.Marshal(header)
+	if err != nil {
+		return "", err
+	}
+	cl, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(h) + "." + base64.RawURLEncoding.EncodeToString(cl)
+	digest := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, c.Key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign client assertion: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// LoadCertificateFile reads a PEM or PFX/PKCS#12 file containing a certificate and its RSA private key.
+func LoadCertificateFile(path, password string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return ParseCertificate(data, password)
+}
+
+// ParseCertificate parses PEM or PFX/PKCS#12 data containing a certificate and its RSA private key.
+func ParseCertificate(data []byte, password string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		return parsePFX(data, password)
+	}
+
+	var certs []*x509.Certificate
+	var key *rsa.PrivateKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		switch block.Type {
+		case "CERTIFICATE":
+			c, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			certs = append(certs, c)
+		case "RSA PRIVATE KEY", "PRIVATE KEY":
+			k, err := parsePrivateKey(block.Bytes)
+			if err != nil {
+				return nil, nil, err
+			}
+			key = k
+		}
+	}
+	if len(certs) == 0 {
+		return nil, nil, fmt.Errorf("no certificate found")
+	}
+	if key == nil {
+		return nil, nil, fmt.Errorf("no private key found")
+	}
+	// Pick the leaf that matches the key; files may also carry the issuer chain
+	for _, c := range certs {
+		if pub, ok := c.PublicKey.(*rsa.PublicKey); ok && pub.Equal(&key.PublicKey) {
+			return c, key, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("no certificate matches the private key")
+}
+
+// parsePFX decodes a PFX/PKCS#12 file, including the PBES2/AES and SHA-256 MAC files
+// OpenSSL 3 writes by default.
+func parsePFX(data []byte, password string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	k, cert, _, err := pkcs12.DecodeChain(data, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode PFX certificate: %w", err)
+	}
+	key, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, nil, fmt.Errorf("certificate private key must be RSA")
+	}
+	if pub, ok := cert.PublicKey.(*rsa.PublicKey); !ok || !pub.Equal(&key.PublicKey) {
+		return nil, nil, fmt.Errorf("no certificate matches the private key")
+	}
+	return cert, key, nil
+}
+
+// parsePrivateKey accepts PKCS#8 and PKCS#1 encodings; PFX conversion labels PKCS#1 keys as "PRIVATE KEY".
+func parsePrivateKey(der []byte) (*rsa.PrivateKey, error) {
+	if k, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return k, nil
+	}
+	k, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	rsaKey, ok := k.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("certificate private key must be RSA")
+	}
+	return rsaKey, nil
+}
+
+// CertificateExpiry returns the certificate's NotAfter without needing its private key.
+// PFX files protected by a password cannot be inspected and return ok=false; PFX files
+// that can't be decoded at all are an error.
+func CertificateExpiry(path string) (notAfter time.Time, ok bool, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if !bytes.Contains(data, []byte("-----BEGIN")) {
+		cert, _, err := parsePFX(data, "")
+		if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+			return time.Time{}, false, nil
+		}
+		if err != nil {
+			return time.Time{}, false, err
+		}
+		return cert.NotAfter, true, nil
+	}
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return time.Time{}, false, fmt.Errorf("no certificate found in %s", path)
+		}
+		if block.Type == "CERTIFICATE" {
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return time.Time{}, false, err
+			}
+			return cert.NotAfter, true, nil
+		}
+	}
+}
+
+// CheckCertificate returns an error if the certificate is not currently valid.
+func CheckCertificate(cert *x509.Certificate) error {
+	now := time.Now()
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("certificate expired on %s", cert.NotAfter.Format("2006-01-02"))
+	}
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("certificate is not valid until %s", cert.NotBefore.Format("2006-01-02"))
+	}
+	return nil
+}
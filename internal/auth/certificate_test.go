@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testdata/openssl3*.pfx were made with OpenSSL 3 defaults (PBES2/AES-256-CBC, SHA-256 MAC):
+//
+//	openssl pkcs12 -export -inkey key.pem -in cert.pem -out openssl3.pfx -passout pass:test
+//	openssl pkcs12 -export -inkey key.pem -in cert.pem -out openssl3-nopass.pfx -passout pass:
+
+func TestParseCertificateOpenSSL3PFX(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "openssl3.pfx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, key, err := ParseCertificate(data, "test")
+	if err != nil {
+		t.Fatalf("ParseCertificate: %v", err)
+	}
+	if cert.Subject.CommonName != "codezure-test" || key == nil {
+		t.Fatalf("unexpected certificate %q", cert.Subject.CommonName)
+	}
+	if _, _, err := ParseCertificate(data, "wrong"); err == nil {
+		t.Fatal("ParseCertificate accepted a wrong password")
+	}
+}
+
+func TestCertificateExpiryPFX(t *testing.T) {
+	notAfter, ok, err := CertificateExpiry(filepath.Join("testdata", "openssl3-nopass.pfx"))
+	if err != nil || !ok {
+		t.Fatalf("CertificateExpiry(no password) = %v, %v, %v", notAfter, ok, err)
+	}
+	if !notAfter.After(time.Now()) {
+		t.Fatalf("NotAfter %v is in the past", notAfter)
+	}
+
+	// A password-protected PFX can't be inspected, but isn't invalid either
+	if _, ok, err := CertificateExpiry(filepath.Join("testdata", "openssl3.pfx")); err != nil || ok {
+		t.Fatalf("CertificateExpiry(password) = %v, %v; want false, nil", ok, err)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.pfx")
+	if err := os.WriteFile(bad, []byte("not a pfx"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := CertificateExpiry(bad); err == nil {
+		t.Fatal("CertificateExpiry accepted an undecodable PFX")
+	}
+}
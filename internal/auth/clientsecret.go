@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// ClientSecretCredential runs the OAuth2 client-credentials flow for a service principal.
+type ClientSecretCredential struct {
+	Authority string
+	TenantID  string
+	ClientID  string
+	Secret    string
+}
+
+func (c *ClientSecretCredential) GetToken(scope string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.ClientID},
+		"client_secret": {c.Secret},
+		"scope":         {scope},
+	}
+	tr, err := requestToken(tokenEndpoint(c.Authority, c.TenantID), form)
+	if err != nil {
+		return nil, fmt.Errorf("service principal token request failed: %w", err)
+	}
+	return tr.token(), nil
+}
@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/config"
+	"github.com/OlaHulleberg/codezure/internal/secrets"
+)
+
+// NewCredential returns the token credential for a profile's auth mode.
+func NewCredential(cfg *config.Config, profile string) (Credential, error) {
+	switch cfg.Auth {
+	case "entra":
+		return &AzureCLICredential{TenantID: cfg.Tenant}, nil
+	case "service-principal":
+		secret, err := secrets.GetSecret(profile, secrets.ClientSecret)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve client secret from keychain for profile '%s': %w", profile, err)
+		}
+		return &ClientSecretCredential{Authority: cfg.Authority, TenantID: cfg.Tenant, ClientID: cfg.ClientID, Secret: secret}, nil
+	case "certificate":
+		cert, key, err := loadCertificate(cfg, profile)
+		if err != nil {
+			return nil, err
+		}
+		return &CertificateCredential{Authority: cfg.Authority, TenantID: cfg.Tenant, ClientID: cfg.ClientID, Cert: cert, Key: key}, nil
+	case "managed-identity":
+		return &ManagedIdentityCredential{Endpoint: cfg.IMDSEndpoint, ClientID: cfg.ClientID}, nil
+	case "workload-identity":
+		return NewWorkloadIdentityCredential(cfg)
+	case "login":
+		return &RefreshTokenCredential{
+			Authority: cfg.Authority,
+			TenantID:  cfg.Tenant,
+			ClientID:  cfg.ClientID,
+			Load:      func() (string, error) { return secrets.GetSecret(profile, secrets.RefreshToken) },
+			Save:      func(rt string) error { return secrets.SaveSecret(profile, secrets.RefreshToken, rt) },
+		}, nil
+	default:
+		return nil, fmt.Errorf("auth mode '%s' does not use token credentials", cfg.Auth)
+	}
+}
+
+// loadCertificate reads the profile's certificate from its file path, or from the keychain when no path is set.
+func loadCertificate(cfg *config.Config, profile string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	// PFX password is optional; PEM keys and unprotected PFX files have none
+	password, _ := secrets.GetSecret(profile, secrets.CertificatePassword)
+	if cfg.Certificate != "" {
+		cert, key, err := LoadCertificateFile(cfg.Certificate, password)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load certificate '%s': %w", cfg.Certificate, err)
+		}
+		return cert, key, nil
+	}
+	stored, err := secrets.GetSecret(profile, secrets.Certificate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to retrieve certificate from keychain for profile '%s': %w", profile, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(stored)
+	if err != nil {
+		return nil, nil, fmt.Errorf("keychain certificate for profile '%s' is corrupt: %w", profile, err)
+	}
+	cert, key, err := ParseCertificate(data, password)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load keychain certificate: %w", err)
+	}
+	return cert, key, nil
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// DefaultPublicClientID is the Azure CLI's public client application, which is
+	// pre-authorized for Cognitive Services and Azure Resource Manager.
+	DefaultPublicClientID = "04b07795-8ddb-461a-bbee-02f9e1bf7b46"
+	// DefaultLoginTenant lets any work or school account sign in.
+	DefaultLoginTenant = "organizations"
+)
+
+// defaultDeviceCodeExpiry is used when the authority doesn't say how long the code is valid.
+const defaultDeviceCodeExpiry = 15 * time.Minute
+
+// loginScope requests a Cognitive Services token plus a refresh token for later silent renewal.
+const loginScope = CognitiveServicesScope + " offline_access openid profile"
+
+type deviceCodeResponse struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+	Message         string `json:"message"`
+}
+
+// DeviceCodeLogin runs the OAuth2 device code flow. show is called with the sign-in
+// instructions for the user; the returned refresh token should be cached for later launches.
+func DeviceCodeLogin(authority, tenant, clientID string, show func(message string)) (string, error) {
+	tenant, clientID = loginDefaults(tenant, clientID)
+	resp, err := httpClient.PostForm(authorityURL(authority, tenant)+"/oauth2/v2.0/devicecode", url.Values{
+		"client_id": {clientID},
+		"scope":     {loginScope},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var dc deviceCodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dc); err != nil || resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("device code request failed with status %d", resp.StatusCode)
+	}
+	show(dc.Message)
+
+	interval := time.Duration(dc.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	expires := time.Duration(dc.ExpiresIn) * time.Second
+	if expires <= 0 {
+		expires = defaultDeviceCodeExpiry
+	}
+	deadline := time.Now().Add(expires)
+	form := url.Values{
+		"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+		"client_id":   {clientID},
+		"device_code": {dc.DeviceCode},
+	}
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+		tr, err := requestToken(tokenEndpoint(authority, tenant), form)
+		if err == nil {
+			if tr.RefreshToken == "" {
+				return "", fmt.Errorf("sign-in succeeded but no refresh token was issued")
+			}
+			return tr.RefreshToken, nil
+		}
+		if tr == nil {
+			return "", err
+		}
+		switch tr.Error {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return "", fmt.Errorf("device code sign-in failed: %w", err)
+		}
+	}
+	return "", fmt.Errorf("device code expired before sign-in completed")
+}
+
+// RefreshTokenCredential silently renews access tokens from a refresh token cached by 'codezure manage login'.
+type RefreshTokenCredential struct {
+	Authority string
+	TenantID  string
+	ClientID  string
+	// Load returns the cached refresh token; Save persists a rotated one.
+	Load func() (string, error)
+	Save func(refreshToken string) error
+}
+
+func (c *RefreshTokenCredential) GetToken(scope string) (*Token, error) {
+	rt, err := c.Load()
+	if err != nil || rt == "" {
+		return nil, fmt.Errorf("not signed in; run 'codezure manage login'")
+	}
+	tenant, clientID := loginDefaults(c.TenantID, c.ClientID)
+	tr, err := requestToken(tokenEndpoint(c.Authority, tenant), url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {clientID},
+		"refresh_token": {rt},
+		"scope":         {scope + " offline_access"},
+	})
+	if err != nil {
+		if tr != nil && tr.Error == "invalid_grant" {
+			return nil, fmt.Errorf("sign-in expired (%w); run 'codezure manage login'", err)
+		}
+		return nil, fmt.Errorf("token refresh failed: %w", err)
+	}
+	// Entra rotates refresh tokens; keep the newest one
+	if tr.RefreshToken != "" && tr.RefreshToken != rt && c.Save != nil {
+		_ = c.Save(tr.RefreshToken)
+	}
+	return tr.token(), nil
+}
+
+func loginDefaults(tenant, clientID string) (string, string) {
+	if tenant == "" {
+		tenant = DefaultLoginTenant
+	}
+	if clientID == "" {
+		clientID = DefaultPublicClientID
+	}
+	return tenant, clientID
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultIMDSEndpoint is the Azure Instance Metadata Service base URL.
+const DefaultIMDSEndpoint = "http://169.254.169.254"
+
+// ManagedIdentityCredential gets tokens from IMDS on Azure VMs and Dev Boxes.
+// ClientID selects a user-assigned identity; empty uses the system-assigned one.
+type ManagedIdentityCredential struct {
+	Endpoint string
+	ClientID string
+}
+
+type imdsToken struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresOn        string `json:"expires_on"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func (c *ManagedIdentityCredential) GetToken(scope string) (*Token, error) {
+	base := strings.TrimRight(strings.TrimSpace(c.Endpoint), "/")
+	if base == "" {
+		base = DefaultIMDSEndpoint
+	}
+	q := url.Values{
+		"api-version": {"2018-02-01"},
+		// IMDS takes a v1 resource, not a v2 scope
+		"resource": {strings.TrimSuffix(scope, "/.default")},
+	}
+	if c.ClientID != "" {
+		q.Set("client_id", c.ClientID)
+	}
+	req, err := http.NewRequest(http.MethodGet, base+"/metadata/identity/oauth2/token?"+q.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata", "true")
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("managed identity endpoint unreachable (is this an Azure VM with an identity assigned?): %w", err)
+	}
+	defer resp.Body.Close()
+	var t imdsToken
+	if err := json.NewDecoder(resp.Body).Decode(&t); err != nil {
+		return nil, fmt.Errorf("managed identity endpoint returned status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || t.AccessToken == "" {
+		if t.ErrorDescription != "" {
+			return nil, fmt.Errorf("managed identity token request failed: %s: %s", t.Error, t.ErrorDescription)
+		}
+		return nil, fmt.Errorf("managed identity endpoint returned status %d", resp.StatusCode)
+	}
+	tok := &Token{AccessToken: t.AccessToken}
+	if exp, err := strconv.ParseInt(t.ExpiresOn, 10, 64); err == nil {
+		tok.ExpiresOn = time.Unix(exp, 0)
+	}
+	return tok, nil
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAuthority is the Entra ID public cloud authority host.
+const DefaultAuthority = "https://login.microsoftonline.com"
+
+// httpClient is shared by token and device code requests so a stalled authority can't hang a launch.
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// tokenEndpoint builds the v2.0 token endpoint for a tenant under the given authority.
+func tokenEndpoint(authority, tenant string) string {
+	return authorityURL(authority, tenant) + "/oauth2/v2.0/token"
+}
+
+func authorityURL(authority, tenant string) string {
+	a := strings.TrimRight(strings.TrimSpace(authority), "/")
+	if a == "" {
+		a = DefaultAuthority
+	}
+	return a + "/" + url.PathEscape(tenant)
+}
+
+// requestToken posts a form to an OAuth2 token endpoint and decodes the response.
+func requestToken(endpoint string, form url.Values) (*tokenResponse, error) {
+	resp, err := httpClient.PostForm(endpoint, form)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var tr tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK || tr.Error != "" {
+		if tr.ErrorDescription != "" {
+			return &tr, fmt.Errorf("%s: %s", tr.Error, firstLine(tr.ErrorDescription))
+		}
+		return &tr, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+	if tr.AccessToken == "" {
+		return nil, fmt.Errorf("token endpoint returned an empty access token")
+	}
+	return &tr, nil
+}
+
+func (tr *tokenResponse) token() *Token {
+	return &Token{AccessToken: tr.AccessToken, ExpiresOn: time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)}
+}
+
+// firstLine trims Entra error descriptions, which append trace and correlation IDs on later lines.
+func firstLine(s string) string {
+	if i := strings.IndexAny(s, "\r\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"sync"
+	"time"
+)
+
+// refreshMargin renews tokens this long before they expire so in-flight requests never carry a stale one.
+const refreshMargin = 5 * time.Minute
+
+// TokenSource caches a credential's token for one scope and renews it shortly before expiry.
+type TokenSource struct {
+	cred  Credential
+	scope string
+
+	mu  sync.Mutex
+	tok *Token
+}
+
+func NewTokenSource(cred Credential, scope string) *TokenSource {
+	return &TokenSource{cred: cred, scope: scope}
+}
+
+// Token returns a valid access token, renewing it when close to expiry.
+func (s *TokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tok != nil && time.Until(s.tok.ExpiresOn) > refreshMargin {
+		return s.tok.AccessToken, nil
+	}
+	tok, err := s.cred.GetToken(s.scope)
+	if err != nil {
+		return "", err
+	}
+	if tok.ExpiresOn.IsZero() {
+		// Unknown lifetime; Entra tokens live at least an hour
+		tok.ExpiresOn = time.Now().Add(time.Hour)
+	}
+	s.tok = tok
+	return tok.AccessToken, nil
+}
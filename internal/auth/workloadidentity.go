@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/OlaHulleberg/codezure/internal/config"
+)
+
+// WorkloadIdentityCredential exchanges a federated OIDC token (GitHub Actions, Kubernetes)
+// for an Entra ID access token.
+type WorkloadIdentityCredential struct {
+	Authority string
+	TenantID  string
+	ClientID  string
+	TokenFile string
+}
+
+// NewWorkloadIdentityCredential resolves workload identity settings from the profile,
+// falling back to the AZURE_* variables injected by the federation provider.
+func NewWorkloadIdentityCredential(cfg *config.Config) (*WorkloadIdentityCredential, error) {
+	c := &WorkloadIdentityCredential{
+		Authority: firstNonEmpty(cfg.Authority, os.Getenv("AZURE_AUTHORITY_HOST")),
+		TenantID:  firstNonEmpty(cfg.Tenant, os.Getenv("AZURE_TENANT_ID")),
+		ClientID:  firstNonEmpty(cfg.ClientID, os.Getenv("AZURE_CLIENT_ID")),
+		TokenFile: firstNonEmpty(cfg.FederatedTokenFile, os.Getenv("AZURE_FEDERATED_TOKEN_FILE")),
+	}
+	if c.TenantID == "" || c.ClientID == "" {
+		return nil, fmt.Errorf("workload identity needs tenant/client_id in the profile or AZURE_TENANT_ID/AZURE_CLIENT_ID")
+	}
+	if c.TokenFile == "" {
+		return nil, fmt.Errorf("workload identity needs federated_token_file in the profile or AZURE_FEDERATED_TOKEN_FILE")
+	}
+	if _, err := os.Stat(c.TokenFile); err != nil {
+		return nil, fmt.Errorf("federated token file not found: %s", c.TokenFile)
+	}
+	return c, nil
+}
+
+func (c *WorkloadIdentityCredential) GetToken(scope string) (*Token, error) {
+	// Re-read on every request; the provider rotates the file
+	b, err := os.ReadFile(c.TokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read federated token file: %w", err)
+	}
+	assertion := strings.TrimSpace(string(b))
+	if assertion == "" {
+		return nil, fmt.Errorf("federated token file %s is empty", c.TokenFile)
+	}
+	form := url.Values{
+		"grant_type":            {"client_credentials"},
+		"client_id":             {c.ClientID},
+		"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+		"client_assertion":      {assertion},
+		"scope":                 {scope},
+	}
+	tr, err := requestToken(tokenEndpoint(c.Authority, c.TenantID), form)
+	if err != nil {
+		return nil, fmt.Errorf("workload identity token request failed: %w", err)
+	}
+	return tr.token(), nil
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
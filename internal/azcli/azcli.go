@@ -0,0 +1,98 @@
+// Package azcli runs the Azure CLI, optionally inside a profile's own login context.
+package azcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// configDir is the AZURE_CONFIG_DIR used for az calls; empty means the user's global ~/.azure.
+var configDir string
+
+// Dir returns the isolated Azure CLI config dir for a profile: ~/.codezure/az/<profile>.
+func Dir(profile string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".codezure", "az", profile), nil
+}
+
+// UseConfigDir makes later az calls in this process run with AZURE_CONFIG_DIR=dir.
+// An empty dir restores the global login context.
+func UseConfigDir(dir string) { configDir = dir }
+
+// ConfigDir returns the config dir set by UseConfigDir.
+func ConfigDir() string { return configDir }
+
+// SignedIn returns the accounts signed in to the current login context, read from its
+// azureProfile.json without running az. It is "" when nobody is signed in or the file
+// can't be read, so callers can use it to tell login contexts apart but not to check auth.
+func SignedIn() string {
+	dir := configDir
+	if dir == "" {
+		dir = os.Getenv("AZURE_CONFIG_DIR")
+	}
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".azure")
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "azureProfile.json"))
+	if err != nil {
+		return ""
+	}
+	var profile struct {
+		Subscriptions []struct {
+			User struct {
+				Name string `json:"name"`
+				Type string `json:"type"`
+			} `json:"user"`
+		} `json:"subscriptions"`
+	}
+	// az writes the file with a UTF-8 byte order mark
+	if err := json.Unmarshal(bytes.TrimPrefix(b, []byte("\xef\xbb\xbf")), &profile); err != nil {
+		return ""
+	}
+	seen := map[string]bool{}
+	var users []string
+	for _, s := range profile.Subscriptions {
+		u := s.User.Type + ":" + strings.ToLower(s.User.Name)
+		if s.User.Name != "" && !seen[u] {
+			seen[u] = true
+			users = append(users, u)
+		}
+	}
+	sort.Strings(users)
+	return strings.Join(users, ",")
+}
+
+// Require returns an error when the Azure CLI is not installed.
+func Require() error {
+	if _, err := exec.LookPath("az"); err != nil {
+		return fmt.Errorf("Azure CLI (az) not found. Install from https://aka.ms/azcli and run 'az login'.")
+	}
+	return nil
+}
+
+// Command returns an az command bound to the current login context.
+func Command(args ...string) *exec.Cmd {
+	cmd := exec.Command("az", args...)
+	if configDir != "" {
+		cmd.Env = append(os.Environ(), "AZURE_CONFIG_DIR="+configDir)
+	}
+	return cmd
+}
+
+// Output runs az with args and returns its standard output.
+func Output(args ...string) ([]byte, error) {
+	return Command(args...).Output()
+}
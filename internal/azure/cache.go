@@ -0,0 +1,100 @@
+package azure
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/config"
+	"github.com/OlaHulleberg/codezure/internal/secrets"
+	"os"
+	"strings"
+	"time"
+)
+
+// cachedCredential is an azure-cli key and endpoint kept in the secret store between launches.
+// The resource it was fetched for is recorded so a changed profile never reuses it.
+type cachedCredential struct {
+	Subscription string    `json:"subscription"`
+	Group        string    `json:"group"`
+	Resource     string    `json:"resource"`
+	Key          string    `json:"key"`
+	Endpoint     string    `json:"endpoint"`
+	Expires      time.Time `json:"expires"`
+}
+
+// CacheTTL returns the profile's credential cache lifetime; zero disables caching.
+func CacheTTL(cfg *config.Config) time.Duration {
+	if strings.TrimSpace(cfg.CacheTTL) == "" {
+		return config.DefaultCacheTTL
+	}
+	d, err := time.ParseDuration(cfg.CacheTTL)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// CachedKeyAndEndpoint returns the azure-cli key and endpoint, reusing a cached pair while it
+// is fresh. refresh bypasses the cache. A cached key that the endpoint rejects is dropped and
+// fetched again; if the endpoint can't be reached, the cached key is used as is.
+func CachedKeyAndEndpoint(profile string, cfg *config.Config, refresh bool) (string, string, error) {
+	ttl := CacheTTL(cfg)
+	if ttl <= 0 || !secrets.Unattended() {
+		return FetchKeyAndEndpoint(cfg)
+	}
+	if !refresh {
+		if c := loadCachedCredential(profile, cfg); c != nil {
+			err := Preflight(c.Endpoint, c.Key)
+			if err == nil || !errors.Is(err, ErrKeyRejected) {
+				return c.Key, c.Endpoint, nil
+			}
+			fmt.Fprintln(os.Stderr, "Cached API key was rejected by the endpoint; fetching a new one")
+		}
+	}
+	_ = InvalidateCachedCredential(profile)
+	key, endpoint, err := FetchKeyAndEndpoint(cfg)
+	if err != nil {
+		return "", "", err
+	}
+	b, err := json.Marshal(cachedCredential{
+		Subscription: cfg.Subscription,
+		Group:        cfg.Group,
+		Resource:     cfg.Resource,
+		Key:          key,
+		Endpoint:     endpoint,
+		Expires:      time.Now().Add(ttl),
+	})
+	if err == nil {
+		// Caching is best effort; the launch doesn't depend on it
+		_ = secrets.SaveSecret(profile, secrets.CachedCredential, string(b))
+	}
+	return key, endpoint, nil
+}
+
+// InvalidateCachedCredential drops a profile's cached key and endpoint, if any.
+func InvalidateCachedCredential(profile string) error {
+	if !secrets.Unattended() {
+		return nil
+	}
+	err := secrets.DeleteSecret(profile, secrets.CachedCredential)
+	if errors.Is(err, secrets.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+func loadCachedCredential(profile string, cfg *config.Config) *cachedCredential {
+	v, err := secrets.GetSecret(profile, secrets.CachedCredential)
+	if err != nil {
+		return nil
+	}
+	var c cachedCredential
+	if err := json.Unmarshal([]byte(v), &c); err != nil {
+		return nil
+	}
+	if time.Now().After(c.Expires) || c.Key == "" || c.Endpoint == "" ||
+		c.Subscription != cfg.Subscription || c.Group != cfg.Group || c.Resource != cfg.Resource {
+		return nil
+	}
+	return &c
+}
@@ -0,0 +1,101 @@
+package azure
+
+import (
+	"github.com/OlaHulleberg/codezure/internal/arm"
+	"github.com/OlaHulleberg/codezure/internal/auth"
+	"github.com/OlaHulleberg/codezure/internal/config"
+	"strings"
+	"sync"
+)
+
+// ARM settings for discovery and key calls, set from the active profile by Configure.
+var (
+	mu          sync.Mutex
+	armEndpoint string
+	profileCfg  *config.Config
+	profileName string
+	tenantID    string // the profile's tenant, for calls that name no tenant
+	profileCred auth.Credential
+	clients     = map[string]*arm.Client{}
+	subTenants  = map[string]string{} // subscription ID -> tenant ID, learned from listings
+)
+
+// Configure makes discovery and key calls use the profile's ARM endpoint and credential.
+// Modes with an ARM identity of their own use it, so they need no az install; Azure CLI
+// and api-key profiles get tokens from az. The credential is only created on first use.
+func Configure(cfg *config.Config, profile string) {
+	mu.Lock()
+	defer mu.Unlock()
+	armEndpoint = strings.TrimSpace(cfg.ARMEndpoint)
+	profileCfg = cfg
+	profileName = profile
+	tenantID = strings.TrimSpace(cfg.Tenant)
+	profileCred = nil
+	clients = map[string]*arm.Client{}
+}
+
+// UseCredential makes discovery and key calls authenticate with cred, whatever the
+// profile's auth mode, e.g. to run them against an httptest server. Call it after Configure,
+// which resets it.
+func UseCredential(cred auth.Credential) {
+	mu.Lock()
+	defer mu.Unlock()
+	profileCred = cred
+	clients = map[string]*arm.Client{}
+}
+
+// client returns the ARM client for calls in a tenant or subscription. With az as the
+// credential source, tokens are requested for that tenant or the subscription's tenant.
+func client(tenant, subscription string) (*arm.Client, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if profileCred == nil && profileCfg != nil && !usesAzureCLI(profileCfg.Auth) {
+		cred, err := auth.NewCredential(profileCfg, profileName)
+		if err != nil {
+			return nil, err
+		}
+		profileCred = cred
+	}
+	if profileCred != nil {
+		// One identity in one tenant; no per-tenant tokens
+		tenant, subscription = "", ""
+	}
+	if t, ok := subTenants[subscription]; ok && tenant == "" {
+		// Share one token across all subscriptions of a tenant
+		tenant, subscription = t, ""
+	} else if tenant == "" && profileCred == nil && tenantID != "" {
+		tenant, subscription = tenantID, ""
+	}
+	key := tenant + "|" + subscription
+	if c, ok := clients[key]; ok {
+		return c, nil
+	}
+	var cred auth.Credential = &auth.AzureCLICredential{TenantID: tenant, Subscription: subscription}
+	if profileCred != nil {
+		cred = profileCred
+	}
+	c := arm.NewClient(armEndpoint, cred)
+	clients[key] = c
+	return c, nil
+}
+
+// rememberTenants records which tenant each subscription belongs to.
+func rememberTenants(subs []Subscription) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, s := range subs {
+		if s.TenantID != "" {
+			subTenants[s.ID] = s.TenantID
+		}
+	}
+}
+
+// usesAzureCLI reports whether ARM calls for an auth mode take tokens from az. That is the
+// Azure CLI modes, and modes such as api-key that have no ARM identity of their own.
+func usesAzureCLI(mode string) bool {
+	switch mode {
+	case "service-principal", "certificate", "managed-identity", "workload-identity", "login":
+		return false
+	}
+	return true
+}
@@ -1,100 +1,188 @@
 package azure
 
 import (
-	"encoding/json"
 	"fmt"
-	"os/exec"
+	"github.com/OlaHulleberg/codezure/internal/arm"
+	"os"
+	"strings"
 )
 
+type Tenant struct {
+	ID     string `json:"tenantId"`
+	Name   string `json:"displayName"`
+	Domain string `json:"defaultDomain"`
+}
+
 type Subscription struct {
-	ID   string `json:"id"`
-	Name string `json:"name"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	TenantID string `json:"tenantId"`
 }
 
 type OpenAIResource struct {
 	Name          string `json:"name"`
 	ResourceGroup string `json:"resourceGroup"`
 	Location      string `json:"location"`
+	Endpoint      string `json:"endpoint"`
+	Subscription  string `json:"subscription"`
 }
 
 type Deployment struct {
-	Name      string `json:"name"`
-	ModelName string `json:"properties.modelName"`
+	Name                 string `json:"name"`
+	ModelName            string `json:"modelName"`
+	ModelVersion         string `json:"modelVersion"`
+	ModelFormat          string `json:"modelFormat"`
+	SKU                  string `json:"sku"`      // Standard, GlobalStandard, DataZoneStandard, ProvisionedManaged, ...
+	Capacity             int    `json:"capacity"` // thousands of tokens per minute, or PTUs for provisioned SKUs
+	ProvisioningState    string `json:"provisioningState"`
+	RAIPolicy            string `json:"raiPolicy"`
+	VersionUpgradeOption string `json:"versionUpgradeOption"`
+}
+
+// Provisioned reports whether the deployment uses provisioned throughput units
+// (ProvisionedManaged, GlobalProvisionedManaged, DataZoneProvisionedManaged).
+func (d *Deployment) Provisioned() bool {
+	return strings.Contains(d.SKU, "Provisioned")
 }
 
-func ListSubscriptions() ([]Subscription, error) {
-	if err := requireAz(); err != nil {
+// CapacityString formats the capacity in the SKU's unit, e.g. "10K TPM" or "100 PTU".
+func (d *Deployment) CapacityString() string {
+	if d.Capacity == 0 {
+		return ""
+	}
+	if d.Provisioned() {
+		return fmt.Sprintf("%d PTU", d.Capacity)
+	}
+	return fmt.Sprintf("%dK TPM", d.Capacity)
+}
+
+// ListTenants returns the tenants the signed-in account can access.
+func ListTenants() ([]Tenant, error) {
+	c, err := client("", "")
+	if err != nil {
 		return nil, err
 	}
-	out, err := exec.Command("az", "account", "list", "-o", "json").Output()
+	raw, err := c.ListTenants()
 	if err != nil {
 		return nil, err
 	}
-	var subs []Subscription
-	if err := json.Unmarshal(out, &subs); err != nil {
+	tenants := make([]Tenant, len(raw))
+	for i, t := range raw {
+		tenants[i] = Tenant{ID: t.TenantID, Name: t.DisplayName, Domain: t.DefaultDomain}
+	}
+	return tenants, nil
+}
+
+// ListSubscriptions returns the subscriptions in tenant, or in the default tenant when empty.
+func ListSubscriptions(tenant string) ([]Subscription, error) {
+	c, err := client(tenant, "")
+	if err != nil {
+		return nil, err
+	}
+	subs, err := subscriptionsOf(c, tenant)
+	if err != nil {
 		return nil, err
 	}
+	rememberTenants(subs)
+	if tenant != "" && len(subs) == 0 {
+		return nil, fmt.Errorf("no subscriptions found in tenant %s; run 'az login --tenant %s'", tenant, tenant)
+	}
 	return subs, nil
 }
 
-func ListOpenAIResources(subscription string) ([]OpenAIResource, error) {
-	if err := requireAz(); err != nil {
+// subscriptionsOf lists the subscriptions visible to c, limited to tenant when set.
+func subscriptionsOf(c *arm.Client, tenant string) ([]Subscription, error) {
+	raw, err := c.ListSubscriptions()
+	if err != nil {
 		return nil, err
 	}
-	if err := runCmd("az", "account", "set", "--subscription", subscription); err != nil {
-		return nil, err
+	var subs []Subscription
+	for _, s := range raw {
+		if tenant != "" && !strings.EqualFold(s.TenantID, tenant) {
+			continue
+		}
+		subs = append(subs, Subscription{ID: s.SubscriptionID, Name: s.DisplayName, TenantID: s.TenantID})
 	}
-	out, err := exec.Command("az", "cognitiveservices", "account", "list", "--subscription", subscription, "-o", "json").Output()
+	return subs, nil
+}
+
+// ListOpenAIResources lists the OpenAI and AI Services resources in a subscription, with a
+// Resource Graph query when available and the Cognitive Services list API otherwise.
+func ListOpenAIResources(subscription string) ([]OpenAIResource, error) {
+	found, missed, errs := graphResources([]Subscription{{ID: subscription}})
+	if len(missed) == 0 {
+		return found, nil
+	}
+	for _, err := range errs {
+		fmt.Fprintf(os.Stderr, "warning: %v; listing the subscription's accounts instead\n", err)
+	}
+	return listAccounts(subscription)
+}
+
+// listAccounts lists a subscription's OpenAI and AI Services resources through the
+// Cognitive Services API.
+func listAccounts(subscription string) ([]OpenAIResource, error) {
+	c, err := client("", subscription)
 	if err != nil {
 		return nil, err
 	}
-	var raw []map[string]any
-	if err := json.Unmarshal(out, &raw); err != nil {
+	accounts, err := c.ListAccounts(subscription)
+	if err != nil {
 		return nil, err
 	}
 	var res []OpenAIResource
-	for _, r := range raw {
-		kind, _ := r["kind"].(string)
+	for _, a := range accounts {
 		// Filter for OpenAI and AIServices (unified service that includes OpenAI)
-		if kind != "OpenAI" && kind != "AIServices" {
+		if a.Kind != "OpenAI" && a.Kind != "AIServices" {
 			continue
 		}
-		name, _ := r["name"].(string)
-		group, _ := r["resourceGroup"].(string)
-		location, _ := r["location"].(string)
-		res = append(res, OpenAIResource{Name: name, ResourceGroup: group, Location: location})
+		res = append(res, OpenAIResource{Name: a.Name, ResourceGroup: a.ResourceGroup(), Location: a.Location, Endpoint: a.Properties.Endpoint, Subscription: subscription})
 	}
 	return res, nil
 }
 
 func ListDeployments(subscription, resource, group string) ([]Deployment, error) {
-	if err := requireAz(); err != nil {
+	c, err := client("", subscription)
+	if err != nil {
 		return nil, err
 	}
-	if err := runCmd("az", "account", "set", "--subscription", subscription); err != nil {
+	raw, err := c.ListDeployments(subscription, group, resource)
+	if err != nil {
 		return nil, err
 	}
-	out, err := exec.Command("az", "cognitiveservices", "account", "deployment", "list", "--name", resource, "--resource-group", group, "--subscription", subscription, "-o", "json").Output()
+	deps := make([]Deployment, len(raw))
+	for i := range raw {
+		deps[i] = deploymentFromARM(&raw[i])
+	}
+	return deps, nil
+}
+
+// GetDeployment returns one deployment of a resource, read live rather than from the cache.
+func GetDeployment(subscription, resource, group, name string) (*Deployment, error) {
+	c, err := client("", subscription)
 	if err != nil {
 		return nil, err
 	}
-	var raw []map[string]any
-	if err := json.Unmarshal(out, &raw); err != nil {
+	raw, err := c.GetDeployment(subscription, group, resource, name)
+	if err != nil {
 		return nil, err
 	}
-	var deps []Deployment
-	for _, d := range raw {
-		name, _ := d["name"].(string)
-		props, _ := d["properties"].(map[string]any)
-		model := ""
-		if props != nil {
-			if modelObj, ok := props["model"].(map[string]any); ok {
-				model, _ = modelObj["name"].(string)
-			}
-		}
-		deps = append(deps, Deployment{Name: name, ModelName: model})
+	d := deploymentFromARM(raw)
+	return &d, nil
+}
+
+func deploymentFromARM(d *arm.Deployment) Deployment {
+	return Deployment{
+		Name:                 d.Name,
+		ModelName:            d.Properties.Model.Name,
+		ModelVersion:         d.Properties.Model.Version,
+		ModelFormat:          d.Properties.Model.Format,
+		SKU:                  d.SKU.Name,
+		Capacity:             d.SKU.Capacity,
+		ProvisioningState:    d.Properties.ProvisioningState,
+		RAIPolicy:            d.Properties.RAIPolicyName,
+		VersionUpgradeOption: d.Properties.VersionUpgradeOption,
 	}
-	return deps, nil
 }
 
 // No extension management — 'az cognitiveservices' is part of core CLI on modern versions.
@@ -0,0 +1,61 @@
+package azure
+
+import (
+	"encoding/json"
+	"github.com/OlaHulleberg/codezure/internal/auth"
+	"github.com/OlaHulleberg/codezure/internal/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type staticCredential struct{}
+
+func (staticCredential) GetToken(scope string) (*auth.Token, error) {
+	return &auth.Token{AccessToken: "test-token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// TestDiscoveryFallsBackFromResourceGraph runs discovery against a fake ARM that refuses
+// Resource Graph, so resources come from the Cognitive Services list API instead.
+func TestDiscoveryFallsBackFromResourceGraph(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/providers/Microsoft.ResourceGraph"):
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error":{"code":"AuthorizationFailed","message":"no graph"}}`))
+		case strings.HasSuffix(r.URL.Path, "/accounts"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"value": []map[string]any{
+				{"id": "/subscriptions/s1/resourceGroups/g1/providers/Microsoft.CognitiveServices/accounts/r1", "name": "r1", "kind": "OpenAI", "location": "eastus", "properties": map[string]string{"endpoint": "https://r1/"}},
+				{"id": "/subscriptions/s1/resourceGroups/g1/providers/Microsoft.CognitiveServices/accounts/speech", "name": "speech", "kind": "SpeechServices"},
+			}})
+		case strings.HasSuffix(r.URL.Path, "/deployments"):
+			_ = json.NewEncoder(w).Encode(map[string]any{"value": []map[string]any{
+				{"name": "d1", "sku": map[string]any{"name": "ProvisionedManaged", "capacity": 50}, "properties": map[string]any{
+					"model": map[string]string{"format": "OpenAI", "name": "gpt-4o", "version": "2024-08-06"}, "raiPolicyName": "Microsoft.Default"}},
+			}})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	Configure(&config.Config{Auth: "azure-cli", ARMEndpoint: srv.URL}, "test")
+	UseCredential(staticCredential{})
+
+	res, err := ListOpenAIResources("s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || res[0].Name != "r1" || res[0].ResourceGroup != "g1" || res[0].Endpoint != "https://r1/" {
+		t.Fatalf("resources = %+v", res)
+	}
+	deps, err := ListDeployments("s1", "r1", "g1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].ModelVersion != "2024-08-06" || !deps[0].Provisioned() || deps[0].CapacityString() != "50 PTU" {
+		t.Fatalf("deployments = %+v", deps)
+	}
+}
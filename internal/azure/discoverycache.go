@@ -0,0 +1,140 @@
+package azure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/azcli"
+	"github.com/OlaHulleberg/codezure/internal/config"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Discovery lists subscriptions, resources and deployments through an on-disk cache in
+// ~/.codezure/cache, so the wizard and 'manage models list' don't wait on ARM every time.
+type Discovery struct {
+	TTL     time.Duration // zero disables the cache
+	Refresh bool          // ignore cached entries, but still store fresh results
+}
+
+// NewDiscovery returns a Discovery using the profile's discovery_cache_ttl.
+func NewDiscovery(cfg *config.Config, refresh bool) *Discovery {
+	return &Discovery{TTL: DiscoveryCacheTTL(cfg), Refresh: refresh}
+}
+
+// DiscoveryCacheTTL returns the profile's discovery cache lifetime; zero disables caching.
+func DiscoveryCacheTTL(cfg *config.Config) time.Duration {
+	if strings.TrimSpace(cfg.DiscoveryCacheTTL) == "" {
+		return config.DefaultDiscoveryCacheTTL
+	}
+	d, err := time.ParseDuration(cfg.DiscoveryCacheTTL)
+	if err != nil || d < 0 {
+		return 0
+	}
+	return d
+}
+
+// Subscriptions returns ListSubscriptions(tenant) and when it was fetched, or the zero time
+// when it was fetched just now.
+func (d *Discovery) Subscriptions(tenant string) ([]Subscription, time.Time, error) {
+	subs, fetched, err := cached(d, []string{"subscriptions", tenant}, func() ([]Subscription, error) {
+		return ListSubscriptions(tenant)
+	})
+	// Cached listings map subscriptions to tenants just like fresh ones
+	rememberTenants(subs)
+	return subs, fetched, err
+}
+
+// Resources returns ListOpenAIResources(subscription) and when it was fetched.
+func (d *Discovery) Resources(subscription string) ([]OpenAIResource, time.Time, error) {
+	return cached(d, []string{"resources", subscription}, func() ([]OpenAIResource, error) {
+		return ListOpenAIResources(subscription)
+	})
+}
+
+// Deployments returns ListDeployments(subscription, resource, group) and when it was fetched.
+func (d *Discovery) Deployments(subscription, resource, group string) ([]Deployment, time.Time, error) {
+	return cached(d, []string{"deployments", subscription, group, resource}, func() ([]Deployment, error) {
+		return ListDeployments(subscription, resource, group)
+	})
+}
+
+// discoveryCacheVersion is part of every cache key; bump it when cached types gain fields.
+const discoveryCacheVersion = "2"
+
+type cacheEntry[T any] struct {
+	Key     string    `json:"key"`
+	Fetched time.Time `json:"fetched"`
+	Items   []T       `json:"items"`
+}
+
+// cached serves fetch from the cache file for key while it is younger than the TTL.
+// Cache read and write failures fall back to fetching; they never fail the call.
+func cached[T any](d *Discovery, parts []string, fetch func() ([]T, error)) ([]T, time.Time, error) {
+	if d == nil || d.TTL <= 0 {
+		items, err := fetch()
+		return items, time.Time{}, err
+	}
+	key, path := discoveryCacheKey(parts)
+	if !d.Refresh && path != "" {
+		if b, err := os.ReadFile(path); err == nil {
+			var e cacheEntry[T]
+			if json.Unmarshal(b, &e) == nil && e.Key == key && time.Since(e.Fetched) < d.TTL {
+				return e.Items, e.Fetched, nil
+			}
+		}
+	}
+	items, err := fetch()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if path != "" {
+		if b, err := json.Marshal(cacheEntry[T]{Key: key, Fetched: time.Now(), Items: items}); err == nil {
+			if os.MkdirAll(filepath.Dir(path), 0o700) == nil {
+				_ = os.WriteFile(path, b, 0o600)
+			}
+		}
+	}
+	return items, time.Time{}, nil
+}
+
+// discoveryCacheKey builds the cache key and file for a listing. The key includes the ARM
+// endpoint and login context, and for az the signed-in accounts, so different identities
+// never share entries, even after 'az login' as someone else.
+func discoveryCacheKey(parts []string) (string, string) {
+	mu.Lock()
+	endpoint, identity := armEndpoint, ""
+	if profileCfg != nil && !usesAzureCLI(profileCfg.Auth) {
+		identity = profileName
+	} else {
+		identity = azcli.ConfigDir() + "|" + azcli.SignedIn()
+	}
+	mu.Unlock()
+	key := strings.Join(append([]string{discoveryCacheVersion, endpoint, identity}, parts...), "|")
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return key, ""
+	}
+	sum := sha256.Sum256([]byte(key))
+	name := parts[0] + "-" + hex.EncodeToString(sum[:8]) + ".json"
+	return key, filepath.Join(home, ".codezure", "cache", name)
+}
+
+// CachedAgo describes the age of a cached result, e.g. "cached 12 minutes ago".
+func CachedAgo(fetched time.Time) string {
+	age := time.Since(fetched)
+	switch {
+	case age < time.Minute:
+		return "cached just now"
+	case age < 2*time.Minute:
+		return "cached 1 minute ago"
+	case age < time.Hour:
+		return fmt.Sprintf("cached %d minutes ago", int(age.Minutes()))
+	case age < 2*time.Hour:
+		return "cached 1 hour ago"
+	}
+	return fmt.Sprintf("cached %d hours ago", int(age.Hours()))
+}
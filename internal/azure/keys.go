@@ -0,0 +1,72 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// AccountKeys holds both access keys of a Cognitive Services account.
+type AccountKeys struct {
+	Key1 string `json:"key1"`
+	Key2 string `json:"key2"`
+}
+
+// Get returns the key with the given name ("key1" or "key2").
+func (k *AccountKeys) Get(name string) string {
+	if name == "key2" {
+		return k.Key2
+	}
+	return k.Key1
+}
+
+// OtherKey returns the name of the key that is not name.
+func OtherKey(name string) string {
+	if name == "key1" {
+		return "key2"
+	}
+	return "key1"
+}
+
+// ListKeys returns both access keys of an account.
+func ListKeys(subscription, resource, group string) (*AccountKeys, error) {
+	c, err := client("", subscription)
+	if err != nil {
+		return nil, err
+	}
+	k, err := c.ListKeys(subscription, group, resource)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountKeys{Key1: k.Key1, Key2: k.Key2}, nil
+}
+
+// RegenerateKey regenerates one access key ("key1" or "key2") and returns the new key pair.
+func RegenerateKey(subscription, resource, group, keyName string) (*AccountKeys, error) {
+	c, err := client("", subscription)
+	if err != nil {
+		return nil, err
+	}
+	k, err := c.RegenerateKey(subscription, group, resource, keyName)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountKeys{Key1: k.Key1, Key2: k.Key2}, nil
+}
+
+// SelectKey picks the key to use: key1, or key2 when the endpoint rejects key1 (e.g. mid-rotation).
+// If the preflight cannot reach the endpoint, key1 is used without failing the launch.
+func SelectKey(endpoint string, keys *AccountKeys) (string, string, error) {
+	err := Preflight(endpoint, keys.Key1)
+	if err == nil || !errors.Is(err, ErrKeyRejected) {
+		return "key1", keys.Key1, nil
+	}
+	if err2 := Preflight(endpoint, keys.Key2); err2 != nil {
+		if errors.Is(err2, ErrKeyRejected) {
+			return "", "", fmt.Errorf("both key1 and key2 were rejected by %s", endpoint)
+		}
+		return "", "", fmt.Errorf("key1 was rejected and key2 could not be checked: %w", err2)
+	}
+	fmt.Fprintln(os.Stderr, "key1 was rejected by the endpoint; using key2")
+	return "key2", keys.Key2, nil
+}
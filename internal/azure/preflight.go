@@ -0,0 +1,39 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrKeyRejected is returned by Preflight when the endpoint rejects the API key (status 401).
+var ErrKeyRejected = errors.New("API key rejected by endpoint")
+
+// Preflight checks an API key against the endpoint by listing models, which costs no tokens.
+func Preflight(endpoint, key string) error {
+	ep := strings.TrimRight(strings.TrimSpace(endpoint), "/")
+	req, err := http.NewRequest(http.MethodGet, ep+"/openai/v1/models", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("api-key", key)
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("preflight request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("%w (status %d)", ErrKeyRejected, resp.StatusCode)
+	case resp.StatusCode == http.StatusForbidden:
+		// Network rules, private endpoints and disabled local auth also answer 403; that says
+		// nothing about the key itself
+		return fmt.Errorf("preflight request returned status 403; check the resource's network access and local auth settings")
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("preflight request returned status %d", resp.StatusCode)
+	}
+	return nil
+}
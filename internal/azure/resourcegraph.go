@@ -0,0 +1,108 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/arm"
+	"net/http"
+	"sort"
+)
+
+// graphUnavailable records tenants where Resource Graph answered as unavailable, so later
+// calls for them go straight to the per-subscription Cognitive Services API for the rest
+// of the process. Other failures (throttling, network, tokens) are retried next time.
+var graphUnavailable = map[string]bool{}
+
+// graphResources lists the OpenAI and AI Services resources in subs with one Resource Graph
+// query per tenant. Subscriptions of tenants where the query failed are returned in missed,
+// for the caller to list through the Cognitive Services API; each failure is in errs, except
+// for tenants already known to be unavailable.
+func graphResources(subs []Subscription) (res []OpenAIResource, missed []Subscription, errs []error) {
+	mu.Lock()
+	byTenant := map[string][]Subscription{}
+	for _, s := range subs {
+		t := s.TenantID
+		if t == "" {
+			t = subTenants[s.ID]
+		}
+		byTenant[t] = append(byTenant[t], s)
+	}
+	mu.Unlock()
+
+	tenants := make([]string, 0, len(byTenant))
+	for t := range byTenant {
+		tenants = append(tenants, t)
+	}
+	sort.Strings(tenants)
+	for _, t := range tenants {
+		accounts, err := graphTenant(t, byTenant[t])
+		if err != nil {
+			missed = append(missed, byTenant[t]...)
+			if !errors.Is(err, errGraphUnavailable) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		for _, a := range accounts {
+			res = append(res, OpenAIResource{
+				Name:          a.Name,
+				ResourceGroup: a.ResourceGroup,
+				Location:      a.Location,
+				Endpoint:      a.Endpoint,
+				Subscription:  a.SubscriptionID,
+			})
+		}
+	}
+	return res, missed, errs
+}
+
+// graphTenant runs the Resource Graph query for the subscriptions of one tenant.
+func graphTenant(tenant string, subs []Subscription) ([]arm.GraphAccount, error) {
+	mu.Lock()
+	known := graphUnavailable[tenant]
+	mu.Unlock()
+	if known {
+		return nil, errGraphUnavailable
+	}
+	ids := make([]string, len(subs))
+	for i, s := range subs {
+		ids[i] = s.ID
+	}
+	sub := ""
+	if tenant == "" && len(ids) == 1 {
+		sub = ids[0] // lets az pick the subscription's tenant
+	}
+	c, err := client(tenant, sub)
+	if err == nil {
+		var accounts []arm.GraphAccount
+		if accounts, err = c.ListOpenAIAccounts(ids); err == nil {
+			return accounts, nil
+		}
+	}
+	if unavailable(err) {
+		mu.Lock()
+		graphUnavailable[tenant] = true
+		mu.Unlock()
+	}
+	if tenant == "" {
+		return nil, fmt.Errorf("Resource Graph query failed: %w", err)
+	}
+	return nil, fmt.Errorf("Resource Graph query for tenant %s failed: %w", tenant, err)
+}
+
+// unavailable reports whether err means Resource Graph can't be used at all, e.g. a cloud
+// that doesn't offer it or a caller without access, as opposed to a transient failure.
+func unavailable(err error) bool {
+	var apiErr *arm.Error
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch apiErr.StatusCode {
+	case http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound:
+		return true
+	}
+	return false
+}
+
+// errGraphUnavailable is returned for tenants where Resource Graph was found unavailable.
+var errGraphUnavailable = errors.New("Resource Graph unavailable")
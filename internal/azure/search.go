@@ -0,0 +1,154 @@
+package azure
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// DefaultParallelism bounds how many subscriptions are queried at once.
+const DefaultParallelism = 8
+
+// ResourceSummary is an OpenAI/AIServices resource with its subscription and deployment count.
+type ResourceSummary struct {
+	Subscription   Subscription
+	Resource       OpenAIResource
+	Deployments    []Deployment
+	DeploymentsErr error // set when the deployments couldn't be listed; the count is unknown
+}
+
+// AllSubscriptions lists the subscriptions of every accessible tenant. Tenants that can't be
+// listed (e.g. no az login for them) are reported in errs and skipped.
+func AllSubscriptions() (subs []Subscription, errs []error) {
+	tenants, err := ListTenants()
+	if err != nil {
+		return nil, []error{err}
+	}
+	seen := map[string]bool{}
+	for _, t := range tenants {
+		c, err := client(t.ID, "")
+		if err == nil {
+			var raw []Subscription
+			raw, err = subscriptionsOf(c, t.ID)
+			for _, s := range raw {
+				if !seen[s.ID] {
+					seen[s.ID] = true
+					subs = append(subs, s)
+				}
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", t.ID, err))
+		}
+	}
+	rememberTenants(subs)
+	return subs, errs
+}
+
+// SearchResources lists the OpenAI/AIServices resources and their deployments in subs.
+// Resources come from one Resource Graph query when available, otherwise from each
+// subscription in turn; deployments are listed for up to parallel subscriptions concurrently.
+// A failing subscription is reported in errs and doesn't stop the others. Results are sorted
+// by subscription and resource name.
+func (d *Discovery) SearchResources(subs []Subscription, parallel int) ([]ResourceSummary, []error) {
+	if parallel < 1 {
+		parallel = DefaultParallelism
+	}
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results []ResourceSummary
+		errs    []error
+	)
+	found, missed, graphErrs := d.graphSearch(subs)
+	for _, err := range graphErrs {
+		errs = append(errs, fmt.Errorf("%w; listing its subscriptions one at a time instead", err))
+	}
+	bySub := map[string][]OpenAIResource{}
+	for _, r := range found {
+		bySub[r.Subscription] = append(bySub[r.Subscription], r)
+	}
+	fallback := map[string]bool{}
+	for _, s := range missed {
+		fallback[s.ID] = true
+	}
+	sem := make(chan struct{}, parallel)
+	for _, s := range subs {
+		wg.Add(1)
+		go func(s Subscription) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			found, err := d.searchSubscription(s, bySub[s.ID], !fallback[s.ID])
+			mu.Lock()
+			defer mu.Unlock()
+			results = append(results, found...)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("subscription %s (%s): %w", s.Name, s.ID, err))
+			}
+		}(s)
+	}
+	wg.Wait()
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Subscription.Name != results[j].Subscription.Name {
+			return results[i].Subscription.Name < results[j].Subscription.Name
+		}
+		return results[i].Resource.Name < results[j].Resource.Name
+	})
+	return results, errs
+}
+
+// searchSubscription lists deployments for a subscription's resources; the resources are
+// looked up unless Resource Graph already returned them (known, fromGraph).
+func (d *Discovery) searchSubscription(s Subscription, known []OpenAIResource, fromGraph bool) ([]ResourceSummary, error) {
+	resources := known
+	if !fromGraph {
+		// Resource Graph already failed for this subscription; go straight to the accounts API
+		var err error
+		resources, _, err = cached(d, []string{"resources", s.ID}, func() ([]OpenAIResource, error) {
+			return listAccounts(s.ID)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	// A resource whose deployments can't be listed is still shown, with an unknown count
+	var out []ResourceSummary
+	var errs []error
+	for _, r := range resources {
+		deps, _, err := d.Deployments(s.ID, r.Name, r.ResourceGroup)
+		if err != nil {
+			err = fmt.Errorf("resource %s: %w", r.Name, err)
+			errs = append(errs, err)
+		}
+		out = append(out, ResourceSummary{Subscription: s, Resource: r, Deployments: deps, DeploymentsErr: err})
+	}
+	return out, errors.Join(errs...)
+}
+
+// graphSearch runs the Resource Graph query for subs through the discovery cache. Results
+// are only cached when every tenant answered; missed lists the subscriptions it couldn't cover.
+func (d *Discovery) graphSearch(subs []Subscription) (found []OpenAIResource, missed []Subscription, errs []error) {
+	ids := make([]string, len(subs))
+	for i, s := range subs {
+		ids[i] = s.ID
+	}
+	sort.Strings(ids)
+	var partial []OpenAIResource
+	found, _, err := cached(d, append([]string{"graph"}, ids...), func() ([]OpenAIResource, error) {
+		res, m, e := graphResources(subs)
+		if len(m) > 0 {
+			partial, missed, errs = res, m, e
+			return nil, errGraphIncomplete
+		}
+		return res, nil
+	})
+	if err != nil {
+		return partial, missed, errs
+	}
+	return found, nil, nil
+}
+
+// errGraphIncomplete keeps a partial Resource Graph result out of the discovery cache.
+var errGraphIncomplete = errors.New("Resource Graph result incomplete")
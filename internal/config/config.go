@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 type Config struct {
 	Subscription string `json:"subscription"`
 	Group        string `json:"group"`
@@ -7,6 +9,56 @@ type Config struct {
 	Location     string `json:"location"`
 	Endpoint     string `json:"endpoint"`
 	Deployment   string `json:"deployment"`
-	Thinking     string `json:"thinking,omitempty"` // low|medium|high for thinking models
-	Auth         string `json:"auth,omitempty"`     // "azure-cli" (default) or "api-key"
+	Thinking     string `json:"thinking,omitempty"`     // low|medium|high for thinking models
+	Auth         string `json:"auth,omitempty"`         // one of AuthModes; "azure-cli" when empty
+	Hardened     bool   `json:"hardened,omitempty"`     // keep the real credential out of Codex's environment via the local proxy
+	CacheTTL     string `json:"cache_ttl,omitempty"`    // Go duration to cache the azure-cli key and endpoint; "0" disables, default 15m
+	IsolatedAz   bool   `json:"isolated_az,omitempty"`  // run az with this profile's own login context in ~/.codezure/az/<profile>
+	ARMEndpoint  string `json:"arm_endpoint,omitempty"` // Azure Resource Manager base URL; defaults to https://management.azure.com
+
+	DiscoveryCacheTTL string `json:"discovery_cache_ttl,omitempty"` // Go duration to cache subscription/resource/deployment lists; "0" disables, default 1h
+
+	// API key sources for "api-key" mode; the keychain is used when none is set
+	APIKeyEnv            string `json:"api_key_env,omitempty"`            // env var holding the key; CODEZURE_KEY_<PROFILE> is always checked
+	SecretCommand        string `json:"secret_command,omitempty"`         // e.g. "op read op://team/azure-openai/key"
+	SecretCommandTimeout string `json:"secret_command_timeout,omitempty"` // Go duration, default 30s
+	KeyVaultURL          string `json:"key_vault_url,omitempty"`          // e.g. https://myvault.vault.azure.net
+	KeyVaultSecret       string `json:"key_vault_secret,omitempty"`       // secret name, optionally name/version
+	KeyRotationDays      int    `json:"key_rotation_days,omitempty"`      // warn when a stored key is older; default 90
+
+	// Service principal / sign-in settings. For "login", Tenant and ClientID default to
+	// "organizations" and the Azure CLI public client. In Azure CLI modes, Tenant scopes
+	// discovery and token requests; empty means the tenant az is signed in to.
+	Tenant      string `json:"tenant,omitempty"`
+	ClientID    string `json:"client_id,omitempty"`
+	Authority   string `json:"authority,omitempty"`   // Entra authority host; defaults to https://login.microsoftonline.com
+	Certificate string `json:"certificate,omitempty"` // PEM/PFX path for certificate auth; empty means stored in keychain
+
+	// Managed identity settings; ClientID selects a user-assigned identity
+	IMDSEndpoint string `json:"imds_endpoint,omitempty"` // defaults to http://169.254.169.254
+
+	// Workload identity settings; falls back to AZURE_FEDERATED_TOKEN_FILE
+	FederatedTokenFile string `json:"federated_token_file,omitempty"`
 }
+
+// AuthModes lists the supported values for Config.Auth.
+var AuthModes = []string{"azure-cli", "api-key", "entra", "service-principal", "certificate", "managed-identity", "workload-identity", "login"}
+
+// ValidAuth reports whether mode is a supported auth mode.
+func ValidAuth(mode string) bool {
+	for _, m := range AuthModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultKeyRotationDays is the key age after which 'manage secrets status' warns.
+const DefaultKeyRotationDays = 90
+
+// DefaultCacheTTL is how long azure-cli launches reuse a cached key and endpoint.
+const DefaultCacheTTL = 15 * time.Minute
+
+// DefaultDiscoveryCacheTTL is how long discovery results in ~/.codezure/cache are reused.
+const DefaultDiscoveryCacheTTL = time.Hour
@@ -1,15 +1,21 @@
 package interactive
 
 import (
+	"encoding/base64"
 	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/apikey"
+	"github.com/OlaHulleberg/codezure/internal/auth"
 	"github.com/OlaHulleberg/codezure/internal/azure"
 	"github.com/OlaHulleberg/codezure/internal/config"
 	"github.com/OlaHulleberg/codezure/internal/profiles"
 	"github.com/OlaHulleberg/codezure/internal/secrets"
+	"os"
+	"time"
 )
 
-// RunInteractiveConfig runs an interactive configuration wizard using Bubbletea selector
-func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
+// RunInteractiveConfig runs an interactive configuration wizard using Bubbletea selector.
+// refresh reloads subscriptions, resources and deployments instead of using the discovery cache.
+func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager, refresh bool) error {
 	cfg, err := mgr.GetCurrentConfig(currentVersion)
 	if err != nil {
 		// No current profile; start with defaults and proceed with interactive GUI
@@ -24,6 +30,12 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 	// Choose authentication method
 	authOpts := []SelectOption{
 		{ID: "azure-cli", Display: "Azure CLI (recommended)"},
+		{ID: "entra", Display: "Entra ID token via Azure CLI (no key access needed)"},
+		{ID: "service-principal", Display: "Service principal with client secret (CI / build agents)"},
+		{ID: "certificate", Display: "Service principal with certificate"},
+		{ID: "managed-identity", Display: "Managed identity (Azure VM / Dev Box)"},
+		{ID: "workload-identity", Display: "Workload identity federation (GitHub Actions / Kubernetes)"},
+		{ID: "login", Display: "Built-in Entra sign-in (browser or device code, no Azure CLI)"},
 		{ID: "api-key", Display: "Keychain API Key (manual)"},
 	}
 	defaultAuth := cfg.Auth
@@ -35,9 +47,19 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 		return fmt.Errorf("authentication selection failed: %w", err)
 	}
 
-	if authMode == "azure-cli" {
+	if authMode == "azure-cli" || authMode == "entra" {
+		// Discover with the Azure CLI sign-in even if the profile used another mode before
+		azure.Configure(&config.Config{Auth: authMode, ARMEndpoint: cfg.ARMEndpoint}, currentProfile)
+		disc := azure.NewDiscovery(cfg, refresh)
+
+		// Tenant first, so customer and home tenants don't get mixed up
+		tenantID, err := selectTenant(cfg.Tenant)
+		if err != nil {
+			return err
+		}
+
 		// Subscriptions
-		subs, err := azure.ListSubscriptions()
+		subs, fetched, err := disc.Subscriptions(tenantID)
 		if err != nil {
 			return fmt.Errorf("failed to list subscriptions: %w", err)
 		}
@@ -45,13 +67,13 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 		for i, s := range subs {
 			subOpts[i] = SelectOption{ID: s.ID, Display: fmt.Sprintf("%s (%s)", s.Name, s.ID)}
 		}
-		subID, err := InteractiveSelect("Select Subscription", "Type to filter subscriptions...", subOpts, cfg.Subscription)
+		subID, err := InteractiveSelect("Select Subscription"+cachedNote(fetched), "Type to filter subscriptions...", subOpts, cfg.Subscription)
 		if err != nil {
 			return fmt.Errorf("subscription selection failed: %w", err)
 		}
 
 		// Resources
-		resList, err := azure.ListOpenAIResources(subID)
+		resList, fetched, err := disc.Resources(subID)
 		if err != nil {
 			return fmt.Errorf("failed to list resources: %w", err)
 		}
@@ -59,7 +81,7 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 		for i, r := range resList {
 			resOpts[i] = SelectOption{ID: r.Name, Display: fmt.Sprintf("%s — rg=%s, region=%s", r.Name, r.ResourceGroup, r.Location)}
 		}
-		resName, err := InteractiveSelect("Select Azure OpenAI Resource", "Type to filter resources...", resOpts, cfg.Resource)
+		resName, err := InteractiveSelect("Select Azure OpenAI Resource"+cachedNote(fetched), "Type to filter resources...", resOpts, cfg.Resource)
 		if err != nil {
 			return fmt.Errorf("resource selection failed: %w", err)
 		}
@@ -70,47 +92,34 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 				break
 			}
 		}
-		endpoint, err := azure.GetEndpoint(subID, res.Name, res.ResourceGroup)
-		if err != nil {
-			return fmt.Errorf("failed to get endpoint: %w", err)
+		endpoint := res.Endpoint
+		if endpoint == "" {
+			endpoint, err = azure.GetEndpoint(subID, res.Name, res.ResourceGroup)
+			if err != nil {
+				return fmt.Errorf("failed to get endpoint: %w", err)
+			}
 		}
 
 		// Deployments (Models)
-		deps, err := azure.ListDeployments(subID, res.Name, res.ResourceGroup)
+		deps, fetched, err := disc.Deployments(subID, res.Name, res.ResourceGroup)
 		if err != nil {
 			return fmt.Errorf("failed to list deployments: %w", err)
 		}
 		depOpts := make([]SelectOption, len(deps))
 		for i, d := range deps {
-			depOpts[i] = SelectOption{ID: d.Name, Display: fmt.Sprintf("%s — model=%s", d.Name, d.ModelName)}
+			depOpts[i] = SelectOption{ID: d.Name, Display: deploymentDisplay(d)}
 		}
-		depName, err := InteractiveSelect("Select Model Deployment", "Type to filter models...", depOpts, cfg.Deployment)
+		depName, err := InteractiveSelect("Select Model Deployment"+cachedNote(fetched), "Type to filter models...", depOpts, cfg.Deployment)
 		if err != nil {
 			return fmt.Errorf("deployment selection failed: %w", err)
 		}
 
 		// Thinking level
-		tl := azure.ThinkingLevels()
-		tlOpts := make([]SelectOption, len(tl))
-		for i, s := range tl {
-			desc := s
-			switch s {
-			case "low":
-				desc = "low — fastest, cheapest"
-			case "medium":
-				desc = "medium — balanced"
-			case "high":
-				desc = "high — deepest reasoning"
-			}
-			tlOpts[i] = SelectOption{ID: s, Display: desc}
-		}
-		thinking, err := InteractiveSelect("Select Thinking Level", "Type to filter levels...", tlOpts, cfg.Thinking)
-		if err != nil {
-			thinking = cfg.Thinking
-		} // allow cancel to keep existing
+		thinking := selectThinking(cfg.Thinking)
 
 		// Update cfg
-		cfg.Auth = "azure-cli"
+		cfg.Auth = authMode
+		cfg.Tenant = tenantID
 		cfg.Subscription = subID
 		cfg.Group = res.ResourceGroup
 		cfg.Resource = res.Name
@@ -120,6 +129,14 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 		if thinking != "" {
 			cfg.Thinking = thinking
 		}
+	} else if authMode == "service-principal" || authMode == "certificate" {
+		return configureServicePrincipal(mgr, cfg, authMode)
+	} else if authMode == "managed-identity" {
+		return configureManagedIdentity(mgr, cfg)
+	} else if authMode == "workload-identity" {
+		return configureWorkloadIdentity(mgr, cfg)
+	} else if authMode == "login" {
+		return configureLogin(mgr, cfg)
 	} else {
 		// Manual keychain-based configuration
 		endpoint, err := InteractiveInput("Enter Azure OpenAI Endpoint", "https://<resource>.openai.azure.com", cfg.Endpoint)
@@ -130,31 +147,52 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 		if err != nil {
 			return fmt.Errorf("deployment input failed: %w", err)
 		}
-		apiKey, err := InteractivePassword("Enter API Key (stored in OS keychain)", "paste API key...")
-		if err != nil {
-			return fmt.Errorf("API key input failed: %w", err)
+		sourceOpts := []SelectOption{
+			{ID: "keychain", Display: "Store the key in the OS keychain"},
+			{ID: "command", Display: "Run a command at launch (pass, 1Password CLI, gopass, script)"},
+			{ID: "keyvault", Display: "Azure Key Vault secret (fetched with your Azure CLI sign-in)"},
 		}
-
-		// Thinking level (optional)
-		tl := azure.ThinkingLevels()
-		tlOpts := make([]SelectOption, len(tl))
-		for i, s := range tl {
-			desc := s
-			switch s {
-			case "low":
-				desc = "low — fastest, cheapest"
-			case "medium":
-				desc = "medium — balanced"
-			case "high":
-				desc = "high — deepest reasoning"
-			}
-			tlOpts[i] = SelectOption{ID: s, Display: desc}
+		defaultSource := "keychain"
+		if cfg.SecretCommand != "" {
+			defaultSource = "command"
+		} else if cfg.KeyVaultURL != "" {
+			defaultSource = "keyvault"
 		}
-		thinking, err := InteractiveSelect("Select Thinking Level", "Type to filter levels...", tlOpts, cfg.Thinking)
+		source, err := InteractiveSelect("Select API Key Source", "Choose where the key comes from...", sourceOpts, defaultSource)
 		if err != nil {
-			thinking = cfg.Thinking
+			return fmt.Errorf("API key source selection failed: %w", err)
+		}
+		var apiKey, secretCommand, vaultURL, vaultSecret string
+		if source == "keyvault" {
+			vaultURL, err = InteractiveInput("Enter Key Vault URL", "https://<vault>.vault.azure.net", cfg.KeyVaultURL)
+			if err != nil {
+				return fmt.Errorf("Key Vault URL input failed: %w", err)
+			}
+			vaultSecret, err = InteractiveInput("Enter Key Vault Secret Name", "<secret-name>", cfg.KeyVaultSecret)
+			if err != nil {
+				return fmt.Errorf("Key Vault secret input failed: %w", err)
+			}
+			if _, err := apikey.GetKeyVaultSecret(vaultURL, vaultSecret, &auth.AzureCLICredential{TenantID: cfg.Tenant}); err != nil {
+				return fmt.Errorf("Key Vault check failed: %w", err)
+			}
+		} else if source == "command" {
+			secretCommand, err = InteractiveInput("Enter Secret Command (prints the API key)", "op read op://vault/azure-openai/key", cfg.SecretCommand)
+			if err != nil {
+				return fmt.Errorf("secret command input failed: %w", err)
+			}
+			if _, err := secrets.RunCommand(secretCommand, 0); err != nil {
+				return fmt.Errorf("secret command check failed: %w", err)
+			}
+		} else {
+			apiKey, err = InteractivePassword("Enter API Key (stored in OS keychain)", "paste API key...")
+			if err != nil {
+				return fmt.Errorf("API key input failed: %w", err)
+			}
 		}
 
+		// Thinking level (optional)
+		thinking := selectThinking(cfg.Thinking)
+
 		// Update cfg
 		cfg.Auth = "api-key"
 		cfg.Subscription = ""
@@ -163,6 +201,9 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 		cfg.Location = ""
 		cfg.Endpoint = endpoint
 		cfg.Deployment = depName
+		cfg.SecretCommand = secretCommand
+		cfg.KeyVaultURL = vaultURL
+		cfg.KeyVaultSecret = vaultSecret
 		if thinking != "" {
 			cfg.Thinking = thinking
 		}
@@ -171,18 +212,26 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 		if err := mgr.SaveCurrentConfig(cfg); err != nil {
 			return fmt.Errorf("failed to save config: %w", err)
 		}
-		// Store the API key in OS keychain under the current profile name
-		profName, e := mgr.GetCurrent()
-		if e != nil || profName == "" {
-			profName = "default"
-		}
-		if err := secrets.SaveKey(profName, apiKey); err != nil {
-			return fmt.Errorf("failed to store API key in keychain: %w", err)
+		if apiKey != "" {
+			// Store the API key in OS keychain under the current profile name
+			profName, e := mgr.GetCurrent()
+			if e != nil || profName == "" {
+				profName = "default"
+			}
+			if err := secrets.SaveKey(profName, apiKey); err != nil {
+				return fmt.Errorf("failed to store API key in keychain: %w", err)
+			}
+			fmt.Printf("\n✓ API key stored in OS keychain for profile '%s'\n", profName)
 		}
 
-		fmt.Printf("\n✓ API key stored in OS keychain for profile '%s'\n", profName)
 		fmt.Printf("\nConfiguration saved successfully!\n")
 		fmt.Printf("\nConfiguration:\n")
+		if cfg.SecretCommand != "" {
+			fmt.Printf("  Key Command:  %s\n", cfg.SecretCommand)
+		}
+		if cfg.KeyVaultURL != "" {
+			fmt.Printf("  Key Vault:    %s (%s)\n", cfg.KeyVaultURL, cfg.KeyVaultSecret)
+		}
 		fmt.Printf("  Endpoint:     %s\n", cfg.Endpoint)
 		fmt.Printf("  Deployment:   %s\n", cfg.Deployment)
 		if cfg.Thinking != "" {
@@ -197,6 +246,9 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 	fmt.Printf("\n✓ Configuration saved successfully to profile '%s'!\n", currentProfile)
 	fmt.Printf("\nConfiguration:\n")
 	fmt.Printf("  Auth Mode:    %s\n", authMode)
+	if cfg.Tenant != "" {
+		fmt.Printf("  Tenant:       %s\n", cfg.Tenant)
+	}
 	fmt.Printf("  Subscription: %s\n", cfg.Subscription)
 	fmt.Printf("  ResourceGrp:  %s\n", cfg.Group)
 	fmt.Printf("  Resource:     %s\n", cfg.Resource)
@@ -208,3 +260,362 @@ func RunInteractiveConfig(currentVersion string, mgr *profiles.Manager) error {
 	}
 	return nil
 }
+
+// configureServicePrincipal prompts for service principal details (client secret or certificate)
+// and stores the credential material in the keychain.
+func configureServicePrincipal(mgr *profiles.Manager, cfg *config.Config, mode string) error {
+	tenant, err := InteractiveInput("Enter Tenant ID", "<tenant-id or domain>", cfg.Tenant)
+	if err != nil {
+		return fmt.Errorf("tenant input failed: %w", err)
+	}
+	clientID, err := InteractiveInput("Enter Client (Application) ID", "<client-id>", cfg.ClientID)
+	if err != nil {
+		return fmt.Errorf("client ID input failed: %w", err)
+	}
+
+	// Secrets to store in the keychain once the profile is saved
+	stored := map[string]string{}
+	certPath := ""
+	if mode == "certificate" {
+		certPath, err = InteractiveInput("Enter Certificate Path (PEM or PFX)", "/path/to/cert.pem", cfg.Certificate)
+		if err != nil {
+			return fmt.Errorf("certificate path input failed: %w", err)
+		}
+		password, err := InteractivePassword("Enter PFX Password (leave empty for PEM)", "optional...")
+		if err != nil {
+			return fmt.Errorf("certificate password input failed: %w", err)
+		}
+		data, err := os.ReadFile(certPath)
+		if err != nil {
+			return fmt.Errorf("failed to read certificate: %w", err)
+		}
+		cert, _, err := auth.ParseCertificate(data, password)
+		if err != nil {
+			return fmt.Errorf("invalid certificate: %w", err)
+		}
+		if err := auth.CheckCertificate(cert); err != nil {
+			return err
+		}
+		storeOpts := []SelectOption{
+			{ID: "file", Display: "Reference the file on disk"},
+			{ID: "keychain", Display: "Import into OS keychain"},
+		}
+		where, err := InteractiveSelect("Certificate Storage", "Choose where the certificate lives...", storeOpts, "file")
+		if err != nil {
+			return fmt.Errorf("certificate storage selection failed: %w", err)
+		}
+		if where == "keychain" {
+			stored[secrets.Certificate] = base64.StdEncoding.EncodeToString(data)
+			certPath = ""
+		}
+		if password != "" {
+			stored[secrets.CertificatePassword] = password
+		}
+	} else {
+		secret, err := InteractivePassword("Enter Client Secret (stored in OS keychain)", "paste client secret...")
+		if err != nil {
+			return fmt.Errorf("client secret input failed: %w", err)
+		}
+		stored[secrets.ClientSecret] = secret
+	}
+
+	endpoint, err := InteractiveInput("Enter Azure OpenAI Endpoint", "https://<resource>.openai.azure.com", cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("endpoint input failed: %w", err)
+	}
+	depName, err := InteractiveInput("Enter Model Deployment Name", "<deployment>", cfg.Deployment)
+	if err != nil {
+		return fmt.Errorf("deployment input failed: %w", err)
+	}
+	thinking := selectThinking(cfg.Thinking)
+
+	cfg.Auth = mode
+	cfg.Tenant = tenant
+	cfg.ClientID = clientID
+	cfg.Certificate = certPath
+	cfg.Endpoint = endpoint
+	cfg.Deployment = depName
+	if thinking != "" {
+		cfg.Thinking = thinking
+	}
+
+	if err := mgr.SaveCurrentConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	profName, e := mgr.GetCurrent()
+	if e != nil || profName == "" {
+		profName = "default"
+	}
+	for name, value := range stored {
+		if err := secrets.SaveSecret(profName, name, value); err != nil {
+			return fmt.Errorf("failed to store %s in keychain: %w", name, err)
+		}
+	}
+
+	if len(stored) > 0 {
+		fmt.Printf("\n✓ Credentials stored in OS keychain for profile '%s'\n", profName)
+	}
+	fmt.Printf("\nConfiguration saved successfully!\n")
+	fmt.Printf("\nConfiguration:\n")
+	fmt.Printf("  Auth Mode:    %s\n", cfg.Auth)
+	fmt.Printf("  Tenant:       %s\n", cfg.Tenant)
+	fmt.Printf("  Client ID:    %s\n", cfg.ClientID)
+	if cfg.Certificate != "" {
+		fmt.Printf("  Certificate:  %s\n", cfg.Certificate)
+	}
+	fmt.Printf("  Endpoint:     %s\n", cfg.Endpoint)
+	fmt.Printf("  Deployment:   %s\n", cfg.Deployment)
+	if cfg.Thinking != "" {
+		fmt.Printf("  Thinking:     %s\n", cfg.Thinking)
+	}
+	return nil
+}
+
+// configureManagedIdentity prompts for an optional user-assigned identity and the endpoint to use.
+func configureManagedIdentity(mgr *profiles.Manager, cfg *config.Config) error {
+	clientID, err := InteractiveInput("Enter User-Assigned Identity Client ID (leave empty for system-assigned)", "<client-id>", cfg.ClientID)
+	if err != nil {
+		return fmt.Errorf("client ID input failed: %w", err)
+	}
+	endpoint, err := InteractiveInput("Enter Azure OpenAI Endpoint", "https://<resource>.openai.azure.com", cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("endpoint input failed: %w", err)
+	}
+	depName, err := InteractiveInput("Enter Model Deployment Name", "<deployment>", cfg.Deployment)
+	if err != nil {
+		return fmt.Errorf("deployment input failed: %w", err)
+	}
+	thinking := selectThinking(cfg.Thinking)
+
+	cfg.Auth = "managed-identity"
+	cfg.ClientID = clientID
+	cfg.Endpoint = endpoint
+	cfg.Deployment = depName
+	if thinking != "" {
+		cfg.Thinking = thinking
+	}
+	if err := mgr.SaveCurrentConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	identity := "system-assigned"
+	if cfg.ClientID != "" {
+		identity = "user-assigned (" + cfg.ClientID + ")"
+	}
+	fmt.Printf("\nConfiguration saved successfully!\n")
+	fmt.Printf("\nConfiguration:\n")
+	fmt.Printf("  Auth Mode:    %s\n", cfg.Auth)
+	fmt.Printf("  Identity:     %s\n", identity)
+	fmt.Printf("  Endpoint:     %s\n", cfg.Endpoint)
+	fmt.Printf("  Deployment:   %s\n", cfg.Deployment)
+	if cfg.Thinking != "" {
+		fmt.Printf("  Thinking:     %s\n", cfg.Thinking)
+	}
+	return nil
+}
+
+// configureWorkloadIdentity prompts for the endpoint; tenant, client ID and token file may be left
+// empty to use the AZURE_* variables provided by the CI/Kubernetes environment at launch.
+func configureWorkloadIdentity(mgr *profiles.Manager, cfg *config.Config) error {
+	tenant, err := InteractiveInput("Enter Tenant ID (leave empty to use AZURE_TENANT_ID)", "<tenant-id>", cfg.Tenant)
+	if err != nil {
+		return fmt.Errorf("tenant input failed: %w", err)
+	}
+	clientID, err := InteractiveInput("Enter Client ID (leave empty to use AZURE_CLIENT_ID)", "<client-id>", cfg.ClientID)
+	if err != nil {
+		return fmt.Errorf("client ID input failed: %w", err)
+	}
+	tokenFile, err := InteractiveInput("Enter Federated Token File (leave empty to use AZURE_FEDERATED_TOKEN_FILE)", "/var/run/secrets/azure/tokens/azure-identity-token", cfg.FederatedTokenFile)
+	if err != nil {
+		return fmt.Errorf("token file input failed: %w", err)
+	}
+	endpoint, err := InteractiveInput("Enter Azure OpenAI Endpoint", "https://<resource>.openai.azure.com", cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("endpoint input failed: %w", err)
+	}
+	depName, err := InteractiveInput("Enter Model Deployment Name", "<deployment>", cfg.Deployment)
+	if err != nil {
+		return fmt.Errorf("deployment input failed: %w", err)
+	}
+	thinking := selectThinking(cfg.Thinking)
+
+	cfg.Auth = "workload-identity"
+	cfg.Tenant = tenant
+	cfg.ClientID = clientID
+	cfg.FederatedTokenFile = tokenFile
+	cfg.Endpoint = endpoint
+	cfg.Deployment = depName
+	if thinking != "" {
+		cfg.Thinking = thinking
+	}
+	if err := mgr.SaveCurrentConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Printf("\nConfiguration saved successfully!\n")
+	fmt.Printf("\nConfiguration:\n")
+	fmt.Printf("  Auth Mode:    %s\n", cfg.Auth)
+	fmt.Printf("  Tenant:       %s\n", orEnv(cfg.Tenant, "AZURE_TENANT_ID"))
+	fmt.Printf("  Client ID:    %s\n", orEnv(cfg.ClientID, "AZURE_CLIENT_ID"))
+	fmt.Printf("  Token File:   %s\n", orEnv(cfg.FederatedTokenFile, "AZURE_FEDERATED_TOKEN_FILE"))
+	fmt.Printf("  Endpoint:     %s\n", cfg.Endpoint)
+	fmt.Printf("  Deployment:   %s\n", cfg.Deployment)
+	if cfg.Thinking != "" {
+		fmt.Printf("  Thinking:     %s\n", cfg.Thinking)
+	}
+	return nil
+}
+
+// configureLogin prompts for the endpoint and signs in with the browser or device code flow,
+// caching the refresh token in the keychain.
+func configureLogin(mgr *profiles.Manager, cfg *config.Config) error {
+	endpoint, err := InteractiveInput("Enter Azure OpenAI Endpoint", "https://<resource>.openai.azure.com", cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("endpoint input failed: %w", err)
+	}
+	depName, err := InteractiveInput("Enter Model Deployment Name", "<deployment>", cfg.Deployment)
+	if err != nil {
+		return fmt.Errorf("deployment input failed: %w", err)
+	}
+	thinking := selectThinking(cfg.Thinking)
+
+	cfg.Auth = "login"
+	cfg.Endpoint = endpoint
+	cfg.Deployment = depName
+	if thinking != "" {
+		cfg.Thinking = thinking
+	}
+	if err := mgr.SaveCurrentConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	profName, e := mgr.GetCurrent()
+	if e != nil || profName == "" {
+		profName = "default"
+	}
+
+	methodOpts := []SelectOption{
+		{ID: "browser", Display: "Browser (opens the system browser)"},
+		{ID: "device-code", Display: "Device code (sign in on another device)"},
+	}
+	method, err := InteractiveSelect("Select Sign-in Method", "Choose how to sign in...", methodOpts, "browser")
+	if err != nil {
+		return fmt.Errorf("sign-in method selection failed: %w", err)
+	}
+	fmt.Println()
+	show := func(msg string) { fmt.Printf("%s\n\n", msg) }
+	var rt string
+	if method == "browser" {
+		rt, err = auth.BrowserLogin(cfg.Authority, cfg.Tenant, cfg.ClientID, show)
+	} else {
+		rt, err = auth.DeviceCodeLogin(cfg.Authority, cfg.Tenant, cfg.ClientID, show)
+	}
+	if err != nil {
+		return fmt.Errorf("sign-in failed: %w", err)
+	}
+	if err := secrets.SaveSecret(profName, secrets.RefreshToken, rt); err != nil {
+		return fmt.Errorf("failed to store refresh token in keychain: %w", err)
+	}
+
+	fmt.Printf("✓ Signed in; refresh token stored in OS keychain for profile '%s'\n", profName)
+	fmt.Printf("\nConfiguration saved successfully!\n")
+	fmt.Printf("\nConfiguration:\n")
+	fmt.Printf("  Auth Mode:    %s\n", cfg.Auth)
+	fmt.Printf("  Endpoint:     %s\n", cfg.Endpoint)
+	fmt.Printf("  Deployment:   %s\n", cfg.Deployment)
+	if cfg.Thinking != "" {
+		fmt.Printf("  Thinking:     %s\n", cfg.Thinking)
+	}
+	return nil
+}
+
+func orEnv(val, env string) string {
+	if val == "" {
+		return "$" + env
+	}
+	return val
+}
+
+// selectThinking prompts for a thinking level; cancelling keeps the current value.
+func selectThinking(current string) string {
+	tl := azure.ThinkingLevels()
+	tlOpts := make([]SelectOption, len(tl))
+	for i, s := range tl {
+		desc := s
+		switch s {
+		case "low":
+			desc = "low — fastest, cheapest"
+		case "medium":
+			desc = "medium — balanced"
+		case "high":
+			desc = "high — deepest reasoning"
+		}
+		tlOpts[i] = SelectOption{ID: s, Display: desc}
+	}
+	thinking, err := InteractiveSelect("Select Thinking Level", "Type to filter levels...", tlOpts, current)
+	if err != nil {
+		return current
+	}
+	return thinking
+}
+
+// selectTenant lets the user pick the tenant to discover resources in. A single tenant is
+// chosen without asking.
+func selectTenant(current string) (string, error) {
+	tenants, err := azure.ListTenants()
+	if err != nil {
+		return "", fmt.Errorf("failed to list tenants: %w", err)
+	}
+	if len(tenants) == 0 {
+		return "", fmt.Errorf("no tenants found; run 'az login'")
+	}
+	if len(tenants) == 1 {
+		return tenants[0].ID, nil
+	}
+	opts := make([]SelectOption, len(tenants))
+	for i, t := range tenants {
+		display := t.ID
+		if t.Name != "" {
+			display = fmt.Sprintf("%s (%s)", t.Name, t.ID)
+		}
+		if t.Domain != "" {
+			display += " — " + t.Domain
+		}
+		opts[i] = SelectOption{ID: t.ID, Display: display}
+	}
+	id, err := InteractiveSelect("Select Tenant", "Type to filter tenants...", opts, current)
+	if err != nil {
+		return "", fmt.Errorf("tenant selection failed: %w", err)
+	}
+	return id, nil
+}
+
+// cachedNote returns a title suffix saying how old a cached discovery result is, or "" when
+// it was fetched just now.
+func cachedNote(fetched time.Time) string {
+	if fetched.IsZero() {
+		return ""
+	}
+	return " (" + azure.CachedAgo(fetched) + "; --refresh to reload)"
+}
+
+// deploymentDisplay describes a deployment for the picker, e.g.
+// "gpt-5 — model=gpt-5 (2025-08-07), GlobalStandard 10K TPM".
+func deploymentDisplay(d azure.Deployment) string {
+	s := fmt.Sprintf("%s — model=%s", d.Name, d.ModelName)
+	if d.ModelVersion != "" {
+		s += fmt.Sprintf(" (%s)", d.ModelVersion)
+	}
+	if d.SKU != "" {
+		s += ", " + d.SKU
+	}
+	if c := d.CapacityString(); c != "" {
+		s += " " + c
+	}
+	if d.RAIPolicy != "" {
+		s += ", RAI " + d.RAIPolicy
+	}
+	if d.ProvisioningState != "" && d.ProvisioningState != "Succeeded" {
+		s += " [" + d.ProvisioningState + "]"
+	}
+	return s
+}
@@ -1,72 +1,104 @@
 package launcher
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/apikey"
+	"github.com/OlaHulleberg/codezure/internal/auth"
 	"github.com/OlaHulleberg/codezure/internal/azure"
-	"github.com/OlaHulleberg/codezure/internal/profiles"
+	"github.com/OlaHulleberg/codezure/internal/config"
+	"github.com/OlaHulleberg/codezure/internal/proxy"
 	"github.com/OlaHulleberg/codezure/internal/secrets"
+	"net/http"
 	"os"
 	"os/exec"
+	"os/signal"
 	"strings"
 )
 
-func Launch(passthrough []string) error {
-	pm, _ := profiles.NewManager()
-	cfg, _ := pm.GetCurrentConfig("dev")
-
+// Launch starts Codex for profileName, whose loaded config is cfg. refresh skips cached
+// credentials.
+func Launch(profileName string, cfg *config.Config, passthrough []string, refresh bool) error {
 	// Determine auth mode (default azure-cli)
-	auth := cfg.Auth
-	if auth == "" {
-		auth = "azure-cli"
+	mode := cfg.Auth
+	if mode == "" {
+		mode = "azure-cli"
 	}
 
 	var key string
 	var endpoint string
 	var err error
+	// Set for Entra ID token modes; requests then go through the refreshing local proxy
+	var tokens *auth.TokenSource
+
+	if profileName == "" {
+		profileName = "default"
+	}
 
-	switch auth {
+	switch mode {
 	case "api-key":
-		// Fetch API key from OS keychain
-		profileName, e := pm.GetCurrent()
-		if e != nil || profileName == "" {
-			profileName = "default"
-		}
-		key, err = secrets.GetKey(profileName)
+		var source string
+		key, source, err = apikey.Resolve(cfg, profileName)
 		if err != nil {
-			return fmt.Errorf("failed to retrieve API key from keychain for profile '%s': %w", profileName, err)
+			return err
 		}
+		fmt.Fprintf(os.Stderr, "Using API key from %s\n", source)
 		endpoint = cfg.Endpoint
 		if endpoint == "" {
 			return fmt.Errorf("endpoint not set in profile; run 'codezure manage config' to configure")
 		}
 	case "azure-cli":
-		key, endpoint, err = azure.FetchKeyAndEndpoint()
+		key, endpoint, err = azure.CachedKeyAndEndpoint(profileName, cfg, refresh)
+		if err != nil {
+			return err
+		}
+	case "entra", "service-principal", "certificate", "managed-identity", "workload-identity", "login":
+		endpoint, tokens, err = tokenSource(cfg, profileName)
 		if err != nil {
 			return err
 		}
 	default:
-		return fmt.Errorf("unknown auth mode: %s", auth)
+		return fmt.Errorf("unknown auth mode: %s", mode)
 	}
 
 	// Build child process environment; avoid mutating global env
 	env := os.Environ()
-	env = append(env, "CODEZURE_API_KEY="+key)
+	baseURL := strings.TrimRight(strings.TrimSpace(endpoint), "/") + "/openai/v1"
 
 	if _, err := exec.LookPath("codex"); err == nil {
-		// Build Codex overrides (non-destructive) and append to passthrough.
-		prof, e := pm.GetCurrent()
-		if e != nil || prof == "" {
-			prof = "default"
+		// Token modes always use the proxy: Codex reads CODEZURE_API_KEY once, but Entra
+		// tokens expire after about an hour. Hardened mode uses it for API keys too, so the
+		// real credential never reaches Codex or the commands it runs.
+		if tokens != nil || cfg.Hardened {
+			authorize := apiKeyAuthorizer(key)
+			if tokens != nil {
+				authorize = tokenAuthorizer(tokens)
+			}
+			key, err = newSessionKey()
+			if err != nil {
+				return err
+			}
+			p, err := proxy.Start(endpoint, key, authorize)
+			if err != nil {
+				return err
+			}
+			defer p.Close()
+			baseURL = p.URL + "/openai/v1"
+		}
+		if cfg.Hardened {
+			env = scrubEnv(env, cfg, profileName)
 		}
+		env = append(env, "CODEZURE_API_KEY="+key)
+
+		// Build Codex overrides (non-destructive) and append to passthrough.
 		// Configure Codex via runtime overrides (no system file writes).
 		// Add only keys the user hasn't already specified.
 		if !hasOverrideKey(passthrough, "model_provider") {
 			// Use codezure provider wired to Azure Responses API
 			passthrough = append(passthrough, "--config", "model_provider=\"codezure\"")
 			passthrough = append(passthrough, "--config", "model_providers.codezure.name=\"Codezure\"")
-			ep := strings.TrimRight(strings.TrimSpace(endpoint), "/")
-			base := ep + "/openai/v1"
-			passthrough = append(passthrough, "--config", fmt.Sprintf("model_providers.codezure.base_url=%q", base))
+			passthrough = append(passthrough, "--config", fmt.Sprintf("model_providers.codezure.base_url=%q", baseURL))
 			passthrough = append(passthrough, "--config", "model_providers.codezure.env_key=\"CODEZURE_API_KEY\"")
 			passthrough = append(passthrough, "--config", "model_providers.codezure.wire_api=\"responses\"")
 		}
@@ -84,13 +116,90 @@ func Launch(passthrough []string) error {
 		cmd.Stderr = os.Stderr
 		cmd.Stdin = os.Stdin
 		cmd.Env = env
+		if tokens != nil || cfg.Hardened {
+			// Keep serving the proxy while Codex handles Ctrl-C itself
+			sig := make(chan os.Signal, 1)
+			signal.Notify(sig, os.Interrupt)
+			defer signal.Stop(sig)
+		}
 		return cmd.Run()
 	}
 	return fmt.Errorf("codex CLI not found on PATH; install Codex and ensure it's on your PATH")
 }
 
-// runLaunch is a small adapter used by cmd/root
-func runLaunch(args []string) error { return Launch(args) }
+// tokenSource resolves the endpoint and a renewing token source for Entra ID token modes.
+// The first token is fetched up front so auth problems surface before Codex starts.
+func tokenSource(cfg *config.Config, profile string) (string, *auth.TokenSource, error) {
+	endpoint := strings.TrimSpace(cfg.Endpoint)
+	if endpoint == "" && cfg.Auth == "entra" {
+		var err error
+		endpoint, err = azure.GetEndpoint(cfg.Subscription, cfg.Resource, cfg.Group)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+	if endpoint == "" {
+		return "", nil, fmt.Errorf("endpoint not set in profile; run 'codezure manage config' to configure")
+	}
+	cred, err := auth.NewCredential(cfg, profile)
+	if err != nil {
+		return "", nil, err
+	}
+	ts := auth.NewTokenSource(cred, auth.CognitiveServicesScope)
+	if _, err := ts.Token(); err != nil {
+		return "", nil, err
+	}
+	return endpoint, ts, nil
+}
+
+func tokenAuthorizer(tokens *auth.TokenSource) proxy.Authorizer {
+	return func(r *http.Request) error {
+		tok, err := tokens.Token()
+		if err != nil {
+			return err
+		}
+		r.Header.Set("Authorization", "Bearer "+tok)
+		return nil
+	}
+}
+
+func apiKeyAuthorizer(key string) proxy.Authorizer {
+	return func(r *http.Request) error {
+		r.Header.Set("api-key", key)
+		return nil
+	}
+}
+
+// scrubEnv drops variables that carry credentials so Codex and its tools can't read them.
+func scrubEnv(env []string, cfg *config.Config, profile string) []string {
+	drop := map[string]bool{
+		apikey.ProfileEnv(profile):   true,
+		secrets.PassphraseEnv:        true,
+		"AZURE_CLIENT_SECRET":        true,
+		"AZURE_FEDERATED_TOKEN_FILE": true,
+	}
+	if cfg.APIKeyEnv != "" {
+		drop[cfg.APIKeyEnv] = true
+	}
+	out := env[:0:0]
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if drop[name] || strings.HasPrefix(name, "CODEZURE_KEY_") {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return out
+}
+
+// newSessionKey returns a random key that only the local proxy accepts.
+func newSessionKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "codezure-" + hex.EncodeToString(b), nil
+}
 
 // hasConfigFlag returns true if passthrough contains --config/-c
 func hasConfigFlag(args []string) bool {
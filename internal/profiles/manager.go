@@ -4,10 +4,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/OlaHulleberg/codezure/internal/auth"
+	"github.com/OlaHulleberg/codezure/internal/azcli"
 	"github.com/OlaHulleberg/codezure/internal/config"
+	"github.com/OlaHulleberg/codezure/internal/secrets"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 )
 
 type Manager struct {
@@ -87,6 +92,205 @@ func (m *Manager) SaveCurrentConfig(cfg *config.Config) error {
 	return writeJSONFile(m.profileFile(name), cfg)
 }
 
+// List returns the names of all saved profiles.
+func (m *Manager) List() ([]string, error) {
+	entries, err := os.ReadDir(m.profiles)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) == ".json" {
+			names = append(names, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+	return names, nil
+}
+
+// Exists reports whether a profile file exists.
+func (m *Manager) Exists(name string) bool {
+	_, err := os.Stat(m.profileFile(name))
+	return err == nil
+}
+
+// Rename renames a profile together with its stored secrets. If the secrets cannot be
+// moved, the file rename is rolled back.
+func (m *Manager) Rename(oldName, newName string) error {
+	cfg, err := m.Load(oldName)
+	if err != nil {
+		return err
+	}
+	if m.Exists(newName) {
+		return fmt.Errorf("profile '%s' already exists", newName)
+	}
+	if err := os.Rename(m.profileFile(oldName), m.profileFile(newName)); err != nil {
+		return err
+	}
+	// Move every known secret, not just those of the current auth mode: a refresh token
+	// saved before switching to login, or a leftover client secret, must move with it
+	rollback := func(err error) error {
+		_ = os.Rename(m.profileFile(newName), m.profileFile(oldName))
+		return fmt.Errorf("failed to move secrets; rename rolled back: %w", err)
+	}
+	stored, err := secrets.Export(oldName)
+	if err != nil {
+		return rollback(err)
+	}
+	if err := secrets.Import(newName, stored); err != nil {
+		return rollback(err)
+	}
+	if err := secrets.Purge(oldName); err != nil {
+		_ = secrets.Purge(newName)
+		_ = secrets.Import(oldName, stored)
+		return rollback(err)
+	}
+	dropCachedCredential(oldName)
+	if cfg.IsolatedAz {
+		// Keep the profile's az login with it; a missing dir just means 'az login' is needed
+		if oldDir, err := azcli.Dir(oldName); err == nil {
+			if newDir, err := azcli.Dir(newName); err == nil {
+				_ = os.Rename(oldDir, newDir)
+			}
+		}
+	}
+	if current, _ := m.GetCurrent(); current == oldName {
+		return m.SetCurrent(newName)
+	}
+	return nil
+}
+
+// Copy copies a profile together with its stored secrets and isolated az login. If either
+// cannot be copied, the new profile file is removed again.
+func (m *Manager) Copy(src, dst string) error {
+	cfg, err := m.Load(src)
+	if err != nil {
+		return err
+	}
+	if m.Exists(dst) {
+		return fmt.Errorf("profile '%s' already exists", dst)
+	}
+	if err := writeJSONFile(m.profileFile(dst), cfg); err != nil {
+		return err
+	}
+	if usesSecrets(cfg) {
+		stored, err := secrets.Export(src)
+		if err == nil {
+			err = secrets.Import(dst, stored)
+		}
+		if err != nil {
+			_ = os.Remove(m.profileFile(dst))
+			return fmt.Errorf("failed to copy secrets; copy rolled back: %w", err)
+		}
+	}
+	if cfg.IsolatedAz {
+		// The copy gets its own az login context, starting signed in as the original
+		if err := copyAzDir(src, dst); err != nil {
+			_ = os.Remove(m.profileFile(dst))
+			if usesSecrets(cfg) {
+				_ = secrets.Purge(dst)
+			}
+			return fmt.Errorf("failed to copy az login; copy rolled back: %w", err)
+		}
+	}
+	return nil
+}
+
+// copyAzDir copies a profile's isolated az config dir. A source without one (not signed in
+// yet) is not an error.
+func copyAzDir(src, dst string) error {
+	from, err := azcli.Dir(src)
+	if err != nil {
+		return err
+	}
+	to, err := azcli.Dir(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(from); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	err = filepath.WalkDir(from, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(from, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(to, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0o700)
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(target, b, 0o600)
+	})
+	if err != nil {
+		_ = os.RemoveAll(to)
+	}
+	return err
+}
+
+// Delete removes a profile and its stored secrets. The current profile cannot be deleted.
+// Secrets are restored if the profile file cannot be removed.
+func (m *Manager) Delete(name string) error {
+	if current, _ := m.GetCurrent(); current == name {
+		return fmt.Errorf("cannot delete current profile")
+	}
+	cfg, err := m.Load(name)
+	if err != nil {
+		return err
+	}
+	// Purge every known secret, whatever the current auth mode, so none outlive the profile
+	stored, err := secrets.Export(name)
+	if err != nil {
+		return fmt.Errorf("failed to read secrets; profile not deleted: %w", err)
+	}
+	if err := secrets.Purge(name); err != nil {
+		_ = secrets.Import(name, stored)
+		return fmt.Errorf("failed to delete secrets; profile not deleted: %w", err)
+	}
+	if err := os.Remove(m.profileFile(name)); err != nil {
+		_ = secrets.Import(name, stored)
+		return err
+	}
+	dropCachedCredential(name)
+	removeAzDir(cfg, name)
+	return nil
+}
+
+// removeAzDir deletes a profile's isolated az login context, signing it out.
+func removeAzDir(cfg *config.Config, name string) {
+	if !cfg.IsolatedAz {
+		return
+	}
+	if dir, err := azcli.Dir(name); err == nil {
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// usesSecrets reports whether a profile's auth mode keeps anything in the secret store.
+// Copies of other profiles skip secret handling, so leftovers from an earlier mode stay put.
+func usesSecrets(cfg *config.Config) bool {
+	switch cfg.Auth {
+	case "api-key", "service-principal", "certificate", "login":
+		return true
+	}
+	return false
+}
+
+// dropCachedCredential removes a profile's cached azure-cli key so it doesn't outlive the
+// profile, whatever its auth mode is now. Deleting never needs the file store passphrase.
+// Best effort: the cache expires on its own.
+func dropCachedCredential(name string) {
+	_ = secrets.DeleteSecret(name, secrets.CachedCredential)
+}
+
 // Load loads a specific profile by name
 func (m *Manager) Load(profileName string) (*config.Config, error) {
 	return readJSONFile(m.profileFile(profileName))
@@ -153,22 +357,94 @@ func readJSONFile(path string) (*config.Config, error) {
 
 func (m *Manager) Validate(cfg *config.Config) error {
 	// Default to azure-cli when auth mode not set (backwards-compatible)
-	auth := strings.TrimSpace(cfg.Auth)
-	if auth == "" {
-		auth = "azure-cli"
+	mode := strings.TrimSpace(cfg.Auth)
+	if mode == "" {
+		mode = "azure-cli"
 	}
 
-	switch auth {
+	if cfg.DiscoveryCacheTTL != "" {
+		if d, err := time.ParseDuration(cfg.DiscoveryCacheTTL); err != nil || d < 0 {
+			return fmt.Errorf("invalid discovery_cache_ttl %q; use a duration like 1h, or 0 to disable", cfg.DiscoveryCacheTTL)
+		}
+	}
+
+	switch mode {
 	case "azure-cli":
 		if strings.TrimSpace(cfg.Subscription) == "" || strings.TrimSpace(cfg.Group) == "" || strings.TrimSpace(cfg.Resource) == "" {
 			return errors.New("subscription/group/resource must be set; run 'codezure manage config'")
 		}
+		if cfg.CacheTTL != "" {
+			if d, err := time.ParseDuration(cfg.CacheTTL); err != nil || d < 0 {
+				return fmt.Errorf("invalid cache_ttl %q; use a duration like 15m, or 0 to disable", cfg.CacheTTL)
+			}
+		}
 	case "api-key":
 		if strings.TrimSpace(cfg.Endpoint) == "" || strings.TrimSpace(cfg.Deployment) == "" {
 			return errors.New("endpoint/deployment must be set; run 'codezure manage config' and choose Keychain auth")
 		}
+		if strings.TrimSpace(cfg.KeyVaultURL) != "" && strings.TrimSpace(cfg.KeyVaultSecret) == "" {
+			return errors.New("key_vault_secret must be set when key_vault_url is set")
+		}
+		if cfg.SecretCommandTimeout != "" {
+			if d, err := time.ParseDuration(cfg.SecretCommandTimeout); err != nil || d <= 0 {
+				return fmt.Errorf("invalid secret_command_timeout %q; use a duration like 30s", cfg.SecretCommandTimeout)
+			}
+		}
+	case "entra":
+		if strings.TrimSpace(cfg.Endpoint) == "" && (strings.TrimSpace(cfg.Subscription) == "" || strings.TrimSpace(cfg.Group) == "" || strings.TrimSpace(cfg.Resource) == "") {
+			return errors.New("endpoint or subscription/group/resource must be set; run 'codezure manage config'")
+		}
+	case "service-principal":
+		if strings.TrimSpace(cfg.Tenant) == "" || strings.TrimSpace(cfg.ClientID) == "" {
+			return errors.New("tenant/client_id must be set for service-principal auth; run 'codezure manage config'")
+		}
+		if strings.TrimSpace(cfg.Endpoint) == "" || strings.TrimSpace(cfg.Deployment) == "" {
+			return errors.New("endpoint/deployment must be set; run 'codezure manage config'")
+		}
+	case "certificate":
+		if strings.TrimSpace(cfg.Tenant) == "" || strings.TrimSpace(cfg.ClientID) == "" {
+			return errors.New("tenant/client_id must be set for certificate auth; run 'codezure manage config'")
+		}
+		if strings.TrimSpace(cfg.Endpoint) == "" || strings.TrimSpace(cfg.Deployment) == "" {
+			return errors.New("endpoint/deployment must be set; run 'codezure manage config'")
+		}
+		if cfg.Certificate != "" {
+			if err := validateCertificateFile(cfg.Certificate); err != nil {
+				return err
+			}
+		}
+	case "managed-identity":
+		if strings.TrimSpace(cfg.Endpoint) == "" || strings.TrimSpace(cfg.Deployment) == "" {
+			return errors.New("endpoint/deployment must be set; run 'codezure manage config'")
+		}
+	case "workload-identity":
+		if strings.TrimSpace(cfg.Endpoint) == "" || strings.TrimSpace(cfg.Deployment) == "" {
+			return errors.New("endpoint/deployment must be set; run 'codezure manage config'")
+		}
+		if _, err := auth.NewWorkloadIdentityCredential(cfg); err != nil {
+			return err
+		}
+	case "login":
+		if strings.TrimSpace(cfg.Endpoint) == "" || strings.TrimSpace(cfg.Deployment) == "" {
+			return errors.New("endpoint/deployment must be set; run 'codezure manage config'")
+		}
 	default:
-		return fmt.Errorf("unknown auth mode: %s", auth)
+		return fmt.Errorf("unknown auth mode: %s", mode)
+	}
+	return nil
+}
+
+// validateCertificateFile checks that a certificate file exists and, when readable without a password, has not expired.
+func validateCertificateFile(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("certificate file not found: %s", path)
+	}
+	notAfter, ok, err := auth.CertificateExpiry(path)
+	if err != nil {
+		return fmt.Errorf("invalid certificate file %s: %w", path, err)
+	}
+	if ok && time.Now().After(notAfter) {
+		return fmt.Errorf("certificate %s expired on %s", path, notAfter.Format("2006-01-02"))
 	}
 	return nil
 }
@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+)
+
+// Authorizer sets upstream credentials on an outgoing request.
+type Authorizer func(r *http.Request) error
+
+// Server is a loopback reverse proxy in front of an Azure OpenAI endpoint. Codex talks to it
+// with a per-session key; the proxy swaps that for real, freshly renewed credentials.
+type Server struct {
+	URL string // e.g. http://127.0.0.1:54321
+
+	srv *http.Server
+	ln  net.Listener
+}
+
+// Start listens on a random loopback port and forwards requests carrying sessionKey to endpoint.
+func Start(endpoint, sessionKey string, authorize Authorizer) (*Server, error) {
+	target, err := url.Parse(strings.TrimRight(strings.TrimSpace(endpoint), "/"))
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("invalid endpoint URL: %s", endpoint)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start local proxy: %w", err)
+	}
+
+	rp := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.Out.Host = target.Host
+		},
+		Transport:     &authTransport{base: http.DefaultTransport, authorize: authorize},
+		FlushInterval: -1, // stream Responses API events as they arrive
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Error(w, "codezure proxy: "+err.Error(), http.StatusBadGateway)
+		},
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validSessionKey(r, sessionKey) {
+			http.Error(w, "codezure proxy: invalid session key", http.StatusUnauthorized)
+			return
+		}
+		// Never forward the session key upstream
+		r.Header.Del("Authorization")
+		r.Header.Del("api-key")
+		rp.ServeHTTP(w, r)
+	})
+
+	s := &Server{
+		URL: "http://" + ln.Addr().String(),
+		srv: &http.Server{Handler: handler},
+		ln:  ln,
+	}
+	go s.srv.Serve(ln)
+	return s, nil
+}
+
+// Close stops the proxy.
+func (s *Server) Close() error { return s.srv.Close() }
+
+func validSessionKey(r *http.Request, sessionKey string) bool {
+	got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if got == "" {
+		got = r.Header.Get("api-key")
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(sessionKey)) == 1
+}
+
+type authTransport struct {
+	base      http.RoundTripper
+	authorize Authorizer
+}
+
+func (t *authTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if err := t.authorize(r); err != nil {
+		return nil, fmt.Errorf("failed to refresh credentials: %w", err)
+	}
+	return t.base.RoundTrip(r)
+}
@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ErrNotFound is returned when no secret is stored under the requested name.
+var ErrNotFound = errors.New("secret not found")
+
+// Backend stores secrets by account name (a profile, or profile/name for extra secrets).
+type Backend interface {
+	SaveKey(account, value string) error
+	GetKey(account string) (string, error)
+	DeleteKey(account string) error
+}
+
+// BackendEnv selects the backend: "keyring", "file" or "auto" (default).
+// Auto uses the OS keyring when it is reachable and the encrypted file store otherwise,
+// e.g. on headless Linux, WSL and containers without Secret Service/D-Bus.
+const BackendEnv = "CODEZURE_SECRETS_BACKEND"
+
+var (
+	selectOnce sync.Once
+	selected   Backend
+	selectErr  error
+)
+
+func current() (Backend, error) {
+	selectOnce.Do(func() { selected, selectErr = selectBackend() })
+	return selected, selectErr
+}
+
+func selectBackend() (Backend, error) {
+	switch name := strings.ToLower(strings.TrimSpace(os.Getenv(BackendEnv))); name {
+	case "keyring":
+		return keyringBackend{}, nil
+	case "file":
+		return newFileBackend()
+	case "", "auto":
+		if keyringAvailable() {
+			return keyringBackend{}, nil
+		}
+		return newFileBackend()
+	default:
+		return nil, fmt.Errorf("unknown %s value %q (want keyring, file or auto)", BackendEnv, name)
+	}
+}
+
+// BackendName reports which backend is in use, for diagnostics.
+func BackendName() string {
+	b, err := current()
+	if err != nil {
+		return "unavailable"
+	}
+	switch b.(type) {
+	case keyringBackend:
+		return "keyring"
+	case *fileBackend:
+		return "file"
+	}
+	return "custom"
+}
+
+// Unattended reports whether secrets can be read and written without prompting for a
+// passphrase, so optional features like caching never block a launch.
+func Unattended() bool {
+	b, err := current()
+	if err != nil {
+		return false
+	}
+	if _, ok := b.(*fileBackend); ok {
+		return os.Getenv(PassphraseEnv) != ""
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultCommandTimeout bounds secret commands when the profile sets no timeout.
+const DefaultCommandTimeout = 30 * time.Second
+
+// RunCommand runs a secret command (e.g. `op read ...`, `pass show ...`) through the shell
+// and returns its stdout with trailing newlines removed.
+func RunCommand(command string, timeout time.Duration) (string, error) {
+	if strings.TrimSpace(command) == "" {
+		return "", fmt.Errorf("secret command is empty")
+	}
+	if timeout <= 0 {
+		timeout = DefaultCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Password managers may need the terminal to unlock
+	cmd.Stdin = os.Stdin
+	// Don't hang on grandchildren that keep stdout open after the command exits or is killed
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if errors.Is(err, exec.ErrWaitDelay) && cmd.ProcessState != nil && cmd.ProcessState.Success() {
+		// The command succeeded but left a background child holding stdout; its output is complete
+		err = nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return "", fmt.Errorf("secret command timed out after %s", timeout)
+	}
+	if err != nil {
+		var ee *exec.ExitError
+		if errors.As(err, &ee) {
+			msg := strings.TrimSpace(stderr.String())
+			if msg == "" {
+				return "", fmt.Errorf("secret command exited with status %d", ee.ExitCode())
+			}
+			return "", fmt.Errorf("secret command exited with status %d: %s", ee.ExitCode(), msg)
+		}
+		return "", fmt.Errorf("failed to run secret command: %w", err)
+	}
+	out := strings.TrimRight(stdout.String(), "\r\n")
+	if strings.TrimSpace(out) == "" {
+		return "", fmt.Errorf("secret command produced no output")
+	}
+	return out, nil
+}
@@ -0,0 +1,17 @@
+package secrets
+
+import (
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestRunCommandBackgroundChild(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	key, err := RunCommand("sleep 3 & echo key", 10*time.Second)
+	if err != nil || key != "key" {
+		t.Fatalf("RunCommand = %q, %v; want key", key, err)
+	}
+}
@@ -0,0 +1,228 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/term"
+)
+
+// PassphraseEnv supplies the file backend passphrase non-interactively (build servers, CI).
+const PassphraseEnv = "CODEZURE_SECRETS_PASSPHRASE"
+
+// fileBackend stores each secret AES-256-GCM encrypted under ~/.codezure/secrets/, with a
+// key derived from a passphrase via scrypt and a random salt per file.
+type fileBackend struct {
+	dir string
+
+	mu         sync.Mutex
+	passphrase string
+}
+
+type encryptedFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+func newFileBackend() (*fileBackend, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(home, ".codezure", "secrets")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	return &fileBackend{dir: dir}, nil
+}
+
+func (f *fileBackend) path(account string) string {
+	return filepath.Join(f.dir, url.PathEscape(account)+".enc")
+}
+
+func (f *fileBackend) SaveKey(account, value string) error {
+	pass, err := f.getPassphrase()
+	if err != nil {
+		return err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	gcm, err := newGCM(pass, salt)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	ef := encryptedFile{Version: 1, Salt: salt, Nonce: nonce, Data: gcm.Seal(nil, nonce, []byte(value), []byte(account))}
+	b, err := json.Marshal(ef)
+	if err != nil {
+		return err
+	}
+	// Write then rename so a crash never leaves a truncated secret behind
+	tmp := f.path(account) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, f.path(account))
+}
+
+func (f *fileBackend) GetKey(account string) (string, error) {
+	b, err := os.ReadFile(f.path(account))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	var ef encryptedFile
+	if err := json.Unmarshal(b, &ef); err != nil {
+		return "", fmt.Errorf("corrupt secret file %s: %w", f.path(account), err)
+	}
+	pass, err := f.getPassphrase()
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(pass, ef.Salt)
+	if err != nil {
+		return "", err
+	}
+	plain, err := gcm.Open(nil, ef.Nonce, ef.Data, []byte(account))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt secret (wrong passphrase?)")
+	}
+	return string(plain), nil
+}
+
+func (f *fileBackend) DeleteKey(account string) error {
+	err := os.Remove(f.path(account))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// getPassphrase reads the passphrase from PassphraseEnv, or prompts once per process on a terminal.
+// The passphrase is checked against an existing secret so a typo can't start encrypting new
+// secrets under a second passphrase; for an empty store, a prompted passphrase is asked twice.
+func (f *fileBackend) getPassphrase() (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.passphrase != "" {
+		return f.passphrase, nil
+	}
+	existing := f.anySecret()
+	if p := os.Getenv(PassphraseEnv); p != "" {
+		if existing != "" && !f.opens(existing, p) {
+			return "", fmt.Errorf("%s does not match the passphrase of the secrets in %s", PassphraseEnv, f.dir)
+		}
+		f.passphrase = p
+		return p, nil
+	}
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("no OS keyring available; set %s to use the encrypted file store", PassphraseEnv)
+	}
+	p, err := readPassphrase(fd, "codezure secrets passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if existing != "" {
+		if !f.opens(existing, p) {
+			return "", fmt.Errorf("wrong passphrase for the secrets in %s", f.dir)
+		}
+	} else {
+		// Nothing to check against yet; make sure the new passphrase was typed as intended
+		confirm, err := readPassphrase(fd, "Confirm new secrets passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if confirm != p {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	f.passphrase = p
+	return p, nil
+}
+
+func readPassphrase(fd int, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	b, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	p := strings.TrimSpace(string(b))
+	if p == "" {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	return p, nil
+}
+
+// anySecret returns the account of one stored secret, or "" when the store is empty.
+func (f *fileBackend) anySecret() string {
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".enc")
+		if !ok || e.IsDir() {
+			continue
+		}
+		account, err := url.PathUnescape(name)
+		if err != nil {
+			continue
+		}
+		var ef encryptedFile
+		if b, err := os.ReadFile(f.path(account)); err == nil && json.Unmarshal(b, &ef) == nil {
+			return account
+		}
+	}
+	return ""
+}
+
+// opens reports whether passphrase decrypts the secret stored for account.
+func (f *fileBackend) opens(account, passphrase string) bool {
+	b, err := os.ReadFile(f.path(account))
+	if err != nil {
+		return false
+	}
+	var ef encryptedFile
+	if err := json.Unmarshal(b, &ef); err != nil {
+		return false
+	}
+	gcm, err := newGCM(passphrase, ef.Salt)
+	if err != nil {
+		return false
+	}
+	_, err = gcm.Open(nil, ef.Nonce, ef.Data, []byte(account))
+	return err == nil
+}
+
+func newGCM(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,24 @@
+package secrets
+
+import "testing"
+
+func TestFileBackendRejectsMismatchedPassphrase(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(PassphraseEnv, "first")
+	f := &fileBackend{dir: dir}
+	if err := f.SaveKey("p", "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv(PassphraseEnv, "typo")
+	g := &fileBackend{dir: dir}
+	if err := g.SaveKey("q", "other"); err == nil {
+		t.Fatal("SaveKey encrypted a secret under a different passphrase")
+	}
+
+	t.Setenv(PassphraseEnv, "first")
+	h := &fileBackend{dir: dir}
+	if v, err := h.GetKey("p"); err != nil || v != "secret" {
+		t.Fatalf("GetKey = %q, %v", v, err)
+	}
+}
@@ -1,36 +1,40 @@
 package secrets
 
 import (
-	"fmt"
+	"errors"
+
 	keyring "github.com/zalando/go-keyring"
 )
 
 // Service name used in OS keychain entries for codezure.
 const serviceName = "codezure"
 
-// SaveKey stores the API key for a given profile in the OS keychain.
-func SaveKey(profile string, apiKey string) error {
-	if profile == "" {
-		return fmt.Errorf("profile name required for keyring entry")
-	}
-	if apiKey == "" {
-		return fmt.Errorf("API key cannot be empty")
-	}
-	return keyring.Set(serviceName, profile, apiKey)
+// keyringBackend stores secrets in the OS keychain (macOS Keychain, Windows Credential
+// Manager, Linux Secret Service).
+type keyringBackend struct{}
+
+func (keyringBackend) SaveKey(account, value string) error {
+	return keyring.Set(serviceName, account, value)
 }
 
-// GetKey retrieves the API key for a given profile from the OS keychain.
-func GetKey(profile string) (string, error) {
-	if profile == "" {
-		return "", fmt.Errorf("profile name required for keyring lookup")
+func (keyringBackend) GetKey(account string) (string, error) {
+	v, err := keyring.Get(serviceName, account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrNotFound
 	}
-	return keyring.Get(serviceName, profile)
+	return v, err
 }
 
-// DeleteKey removes the API key entry for the given profile from the OS keychain.
-func DeleteKey(profile string) error {
-	if profile == "" {
-		return fmt.Errorf("profile name required for keyring delete")
+func (keyringBackend) DeleteKey(account string) error {
+	err := keyring.Delete(serviceName, account)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrNotFound
 	}
-	return keyring.Delete(serviceName, profile)
+	return err
+}
+
+// keyringAvailable probes the OS keyring; a missing entry means it is reachable.
+func keyringAvailable() bool {
+	_, err := keyring.Get(serviceName, "__codezure_probe__")
+	return err == nil || errors.Is(err, keyring.ErrNotFound)
 }
@@ -0,0 +1,182 @@
+package secrets
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SaveKey stores the API key for a given profile in the secret store.
+func SaveKey(profile string, apiKey string) error {
+	if profile == "" {
+		return fmt.Errorf("profile name required for keyring entry")
+	}
+	if apiKey == "" {
+		return fmt.Errorf("API key cannot be empty")
+	}
+	b, err := current()
+	if err != nil {
+		return err
+	}
+	if err := b.SaveKey(profile, apiKey); err != nil {
+		return err
+	}
+	// Record when the key was stored so key age can be reported
+	return b.SaveKey(secretAccount(profile, KeyUpdated), time.Now().UTC().Format(time.RFC3339))
+}
+
+// KeySavedAt returns when the profile's API key was last stored.
+func KeySavedAt(profile string) (time.Time, error) {
+	b, err := current()
+	if err != nil {
+		return time.Time{}, err
+	}
+	v, err := b.GetKey(secretAccount(profile, KeyUpdated))
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, v)
+}
+
+// GetKey retrieves the API key for a given profile from the secret store.
+func GetKey(profile string) (string, error) {
+	if profile == "" {
+		return "", fmt.Errorf("profile name required for keyring lookup")
+	}
+	b, err := current()
+	if err != nil {
+		return "", err
+	}
+	return b.GetKey(profile)
+}
+
+// DeleteKey removes the API key entry for the given profile from the secret store.
+func DeleteKey(profile string) error {
+	if profile == "" {
+		return fmt.Errorf("profile name required for keyring delete")
+	}
+	b, err := current()
+	if err != nil {
+		return err
+	}
+	if err := b.DeleteKey(profile); err != nil {
+		return err
+	}
+	if err := b.DeleteKey(secretAccount(profile, KeyUpdated)); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// Names of additional per-profile secrets stored next to the API key.
+const (
+	ClientSecret        = "client-secret"
+	Certificate         = "certificate" // base64 PEM/PFX bytes when not referenced by path
+	CertificatePassword = "certificate-password"
+	RefreshToken        = "refresh-token"     // cached by 'codezure manage login'
+	KeyUpdated          = "key-updated"       // RFC 3339 time the API key was stored
+	CachedCredential    = "cached-credential" // azure-cli key and endpoint cached between launches
+)
+
+// SaveSecret stores a named secret (e.g. a client secret) for a profile in the secret store.
+func SaveSecret(profile, name, value string) error {
+	if profile == "" {
+		return fmt.Errorf("profile name required for keyring entry")
+	}
+	if value == "" {
+		return fmt.Errorf("%s cannot be empty", name)
+	}
+	b, err := current()
+	if err != nil {
+		return err
+	}
+	return b.SaveKey(secretAccount(profile, name), value)
+}
+
+// GetSecret retrieves a named secret for a profile from the secret store.
+func GetSecret(profile, name string) (string, error) {
+	if profile == "" {
+		return "", fmt.Errorf("profile name required for keyring lookup")
+	}
+	b, err := current()
+	if err != nil {
+		return "", err
+	}
+	return b.GetKey(secretAccount(profile, name))
+}
+
+// DeleteSecret removes a named secret for a profile from the secret store.
+func DeleteSecret(profile, name string) error {
+	if profile == "" {
+		return fmt.Errorf("profile name required for keyring delete")
+	}
+	b, err := current()
+	if err != nil {
+		return err
+	}
+	return b.DeleteKey(secretAccount(profile, name))
+}
+
+func secretAccount(profile, name string) string {
+	if name == "" {
+		return profile // API key
+	}
+	return profile + "/" + name
+}
+
+// names lists every per-profile secret; "" is the API key.
+var names = []string{"", KeyUpdated, ClientSecret, Certificate, CertificatePassword, RefreshToken}
+
+// Export returns every secret stored for a profile, keyed by name ("" for the API key).
+func Export(profile string) (map[string]string, error) {
+	b, err := current()
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	for _, name := range names {
+		v, err := b.GetKey(secretAccount(profile, name))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		out[name] = v
+	}
+	return out, nil
+}
+
+// Import stores exported secrets under a profile. If any write fails, the entries
+// written so far are removed again so the profile is left as it was.
+func Import(profile string, stored map[string]string) error {
+	b, err := current()
+	if err != nil {
+		return err
+	}
+	var written []string
+	for name, v := range stored {
+		if err := b.SaveKey(secretAccount(profile, name), v); err != nil {
+			for _, w := range written {
+				_ = b.DeleteKey(secretAccount(profile, w))
+			}
+			return err
+		}
+		written = append(written, name)
+	}
+	return nil
+}
+
+// Purge removes every secret stored for a profile.
+func Purge(profile string) error {
+	b, err := current()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := b.DeleteKey(secretAccount(profile, name)); err != nil && !errors.Is(err, ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}